@@ -0,0 +1,182 @@
+package kundalini
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/iterators"
+)
+
+// pull collects the elements of a lazy chain once for every caller
+type pull struct {
+	mu   sync.Mutex
+	done bool
+	v    interface{}
+	err  error
+}
+
+// collect drains `it` unless a caller already has
+func (p *pull) collect(it Iterator) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.done {
+		p.v, p.err = iterators.Collect(it)
+		p.done = true
+	}
+}
+
+// collected iterates over the elements collected so far, it is nil until a
+// caller has collected them and waits for one that is collecting
+func (p *pull) collected() Iterator {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case !p.done:
+		return nil
+	case p.err != nil:
+		return iterators.Failed(p.err)
+	}
+	return iterators.FromSlice(reflect.ValueOf(p.v))
+}
+
+// replay is what the stages built on a lazy chain pull from, the chain's
+// source or, when a terminal collected it before the first pull, the
+// elements the terminal collected
+type replay struct {
+	p   *pull
+	src Iterator
+	it  Iterator
+}
+
+func (r *replay) from() Iterator {
+	if r.it == nil {
+		if r.it = r.p.collected(); r.it == nil {
+			r.it = r.src
+		}
+	}
+	return r.it
+}
+
+func (r *replay) Next() (interface{}, bool) { return r.from().Next() }
+
+func (r *replay) Poll() (interface{}, bool, bool) {
+	if p, ok := r.from().(iterators.Poller); ok {
+		return p.Poll()
+	}
+	v, ok := r.it.Next()
+	return v, ok, true
+}
+
+func (r *replay) Err() error {
+	if r.it == nil {
+		return nil
+	}
+	return iterators.Err(r.it)
+}
+
+func (r *replay) ElemType() reflect.Type { return iterators.ElemType(r.src) }
+
+func (r *replay) Close() { iterators.Close(r.from()) }
+
+func (r *replay) String() string { return "<lazy>" }
+
+// source returns the `Iterator` a stage built on a lazy `k` pulls from
+func (k *K) source() (Iterator, bool) {
+	it, ok := k.wrapped.(Iterator)
+	if !ok || k.pulled == nil {
+		return it, ok
+	}
+	return &replay{p: k.pulled, src: it}, true
+}
+
+// lazy prepares `v` to be wrapped, an `Iterator` is locked so that the
+// chains built from it can pull from it concurrently
+func lazy(v interface{}) (interface{}, *pull) {
+	if it, ok := v.(Iterator); ok {
+		return iterators.Locked(it), &pull{}
+	}
+	return v, nil
+}
+
+// mode returns a copy of `k` that records switching to the mode `op` in the
+// lineage, a mode is not a stage
+func (k *K) mode(op string) *K {
+	m := *k
+	m.lineage = newStep(-1, op, nil, k.wrapped, k.stack.len(), k.lineage)
+	return &m
+}
+
+// Serialize makes the functions given to the stages that follow `k` run one
+// at a time, including when chains built from it are evaluated in different
+// goroutines
+// it covers `Map`, `Filter`, `Reduce`, `MapE`, `FilterE`, `MapBatched` and `Tap`
+func (k *K) Serialize() Kundalini {
+	if k.err != nil || k.serial != nil {
+		return k
+	}
+	logrus.Debug("serial: ", k.wrapped)
+	s := k.mode("Serialize")
+	s.serial = &sync.Mutex{}
+	return s
+}
+
+func (k *K) serialFn(fn Fn) Fn {
+	mu := k.serial
+	if mu == nil {
+		return fn
+	}
+	return func(x interface{}) interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return fn(x)
+	}
+}
+
+func (k *K) serialPredicate(p func(interface{}) bool) func(interface{}) bool {
+	mu := k.serial
+	if mu == nil {
+		return p
+	}
+	return func(x interface{}) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return p(x)
+	}
+}
+
+func (k *K) serialTransform(fn Transform) Transform {
+	mu := k.serial
+	if mu == nil {
+		return fn
+	}
+	return func(acc, x interface{}) interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return fn(acc, x)
+	}
+}
+
+func (k *K) serialFnE(fn FnE) FnE {
+	mu := k.serial
+	if mu == nil {
+		return fn
+	}
+	return func(x interface{}) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return fn(x)
+	}
+}
+
+func (k *K) serialPredicateE(p PredicateE) PredicateE {
+	mu := k.serial
+	if mu == nil {
+		return p
+	}
+	return func(x interface{}) (bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return p(x)
+	}
+}
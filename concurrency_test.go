@@ -0,0 +1,187 @@
+package kundalini_test
+
+import (
+	"io/ioutil"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+)
+
+// hammer calls `fn` from `n` goroutines at once and waits for them
+func hammer(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			fn(i)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+}
+
+func debugLogging(t *testing.T) {
+	out, level := logrus.StandardLogger().Out, logrus.GetLevel()
+	logrus.SetOutput(ioutil.Discard)
+	logrus.SetLevel(logrus.DebugLevel)
+	t.Cleanup(func() {
+		logrus.SetOutput(out)
+		logrus.SetLevel(level)
+	})
+}
+
+type Item struct {
+	Name  string `json:"name"`
+	Price int    `json:"price"`
+}
+
+func TestConcurrency(t *testing.T) {
+	debugLogging(t)
+
+	t.Run("should share an eager chain between goroutines", func(t *testing.T) {
+		items := []Item{{"a", 3}, {"b", 1}, {"c", 2}}
+		base := Wrap(items).Push().Filter(func(x interface{}) bool { return x.(Item).Price > 0 })
+		cheap := func(e interface{}) error { return nil }
+
+		hammer(32, func(i int) {
+			names, err := base.Pluck("Name").Release()
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"a", "b", "c"}, names)
+
+			sorted, _ := base.SortByField("price").Pluck("price").Release()
+			assert.Equal(t, []int{1, 2, 3}, sorted)
+
+			buf := []Item{}
+			base.Concat([]Item{{"d", i}}).Take(4).Export(reflect.ValueOf(&buf))
+			assert.Equal(t, Item{"d", i}, buf[3])
+
+			popped, _ := base.Push().Pop().Pop().Release()
+			assert.Equal(t, items, popped)
+
+			base.Types().Release()
+			base.Validate(Rules{Checks: []Rule{cheap}}).SelectMap("name").Release()
+			base.Inspect(ioutil.Discard).Describe(DescribeDOT)
+			_, err = base.MarshalJSON()
+			assert.NoError(t, err)
+			assert.NoError(t, base.ToJSONLines(ioutil.Discard))
+		})
+	})
+
+	t.Run("should collect a lazy chain once for every caller", func(t *testing.T) {
+		k := Wrap(&counter{to: 100}).Map(double)
+		results := make([]interface{}, 16)
+
+		hammer(len(results), func(i int) {
+			results[i], _ = k.Release()
+		})
+
+		for _, r := range results {
+			assert.Len(t, r, 100)
+			assert.Equal(t, results[0], r)
+		}
+	})
+
+	t.Run("should branch a lazy chain after it was collected", func(t *testing.T) {
+		k := Wrap(&counter{to: 5})
+		built := k.Map(double)
+
+		released, err := k.Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{0, 1, 2, 3, 4}, released)
+
+		mapped, err := k.Map(double).Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{0, 2, 4, 6, 8}, mapped)
+		mapped, err = built.Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{0, 2, 4, 6, 8}, mapped)
+		taken, _ := k.Filter(isEven).Take(2).Release()
+		assert.Equal(t, []interface{}{0, 2}, taken)
+		again, _ := k.Release()
+		assert.Equal(t, released, again)
+	})
+
+	t.Run("should share a collected lazy chain between goroutines", func(t *testing.T) {
+		k := Wrap(&counter{to: 100})
+		k.Push().Pop()
+
+		hammer(32, func(i int) {
+			var r interface{}
+			switch i % 4 {
+			case 0:
+				r, _ = k.Release()
+			case 1:
+				r, _ = k.Map(inc).Release()
+			case 2:
+				r, _ = k.Filter(func(interface{}) bool { return true }).Concat([]int{}).Release()
+			default:
+				r, _ = k.TopK(100, byInt).Release()
+			}
+			assert.Len(t, r, 100)
+		})
+	})
+
+	t.Run("should hand each element of a lazy chain to one branch", func(t *testing.T) {
+		k := Wrap(&counter{to: 1000})
+		counts := make([]int, 8)
+
+		hammer(len(counts), func(i int) {
+			r, err := k.Map(inc).Filter(func(interface{}) bool { return true }).Release()
+			assert.NoError(t, err)
+			counts[i] = len(r.([]interface{}))
+		})
+
+		total := 0
+		for _, c := range counts {
+			total += c
+		}
+		assert.Equal(t, 1000, total)
+	})
+
+	t.Run("should run the functions of a serialized chain one at a time", func(t *testing.T) {
+		seen := make(map[int]int)
+		count := func(x interface{}) bool {
+			seen[x.(int)]++
+			return true
+		}
+		sum := func(acc, x interface{}) interface{} {
+			seen[-1]++
+			return acc.(int) + x.(int)
+		}
+		k := Wrap([]int{1, 2, 3}).Serialize()
+
+		hammer(32, func(i int) {
+			k.Filter(count).Map(func(x interface{}) interface{} {
+				seen[0]++
+				return x
+			}).Reduce(0, sum).Tap(func(interface{}) { seen[-2]++ }).Release()
+		})
+
+		assert.Equal(t, map[int]int{-2: 32, -1: 96, 0: 96, 1: 32, 2: 32, 3: 32}, seen)
+	})
+
+	t.Run("should run serialized functions one at a time in dead-letter mode", func(t *testing.T) {
+		calls := 0
+		fn := func(x interface{}) (interface{}, error) {
+			calls++
+			return x, nil
+		}
+		k := Wrap(&counter{to: 50}).Serialize().DeadLetter()
+
+		hammer(8, func(i int) {
+			k.MapE(fn).MapBatched(5, func(b []interface{}) ([]interface{}, error) {
+				calls++
+				return b, nil
+			}).Release()
+		})
+
+		assert.Equal(t, 60, calls)
+	})
+}
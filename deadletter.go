@@ -0,0 +1,207 @@
+package kundalini
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/iterators"
+	"gitlab.com/jdbellamy/kundalini/slices"
+)
+
+// FnE is a `Fn` that can fail
+type FnE func(interface{}) (interface{}, error)
+
+// PredicateE is a `Predicate` that can fail
+type PredicateE func(interface{}) (bool, error)
+
+// Rejection is an element that failed a stage of the chain
+// stages are counted from 0 at the first operation after `Wrap`
+type Rejection struct {
+	Stage int
+	Value interface{}
+	Err   error
+}
+
+func (r *Rejection) Error() string {
+	return fmt.Sprintf("stage %d: %v", r.Stage, r.Err)
+}
+
+// Unwrap returns the error of the stage so that `errors.Is` matches it
+func (r *Rejection) Unwrap() error { return r.Err }
+
+// deadLetters collects the rejections of a chain and every chain built from it
+type deadLetters struct {
+	mu   sync.Mutex
+	list []Rejection
+}
+
+func (d *deadLetters) add(r *Rejection) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.list = append(d.list, *r)
+}
+
+// DeadLetter switches `k` into dead-letter mode, where an element that makes
+// a stage fail is set aside as a `Rejection` instead of failing the chain
+// in dead-letter mode a `Fn` or predicate that panics rejects its element too
+func (k *K) DeadLetter() Kundalini {
+	if k.err != nil || k.letters != nil {
+		return k
+	}
+	logrus.Debug("  dead: ", k.wrapped)
+	d := k.mode("DeadLetter")
+	d.letters = &deadLetters{}
+	return d
+}
+
+// Rejected returns the elements set aside by `k` in dead-letter mode
+// elements of a lazy chain are only rejected once they have been pulled
+func (k *K) Rejected() []Rejection {
+	if k.letters == nil {
+		return nil
+	}
+	k.letters.mu.Lock()
+	defer k.letters.mu.Unlock()
+	return append([]Rejection(nil), k.letters.list...)
+}
+
+// ExportRejected copies the rejections of `k` to the slice at `ptr`
+// a `*[]Rejection` receives them whole, any other slice the rejected values
+// a lazy `k` is collected first so that all of its rejections are known
+func (k *K) ExportRejected(ptr reflect.Value) Kundalini {
+	k = k.drain()
+	if k.err != nil {
+		return k
+	}
+	rejected := k.Rejected()
+	if ptr.Type() == reflect.TypeOf(&rejected) {
+		ptr.Elem().Set(reflect.ValueOf(rejected))
+		return k
+	}
+	values := make([]interface{}, len(rejected))
+	for i, r := range rejected {
+		values[i] = r.Value
+	}
+	slices.Export(reflect.ValueOf(values), ptr)
+	return k
+}
+
+// MapE applys `fn` over each element of `k` like `Map`
+// an element `fn` fails on is rejected in dead-letter mode, otherwise its
+// `*Rejection` becomes the chain's error
+// `opts` can retry failing elements and time out slow ones
+func (k *K) MapE(fn FnE, opts ...StageOption) Kundalini {
+	return k.mapE("MapE", fn, withOptions(k.serialFnE(fn), opts))
+}
+
+// mapE is `MapE` recorded in the lineage as `op` applying `named`
+func (k *K) mapE(op string, named interface{}, fn FnE) Kundalini {
+	if k.err != nil {
+		return k
+	}
+	stage := k.stage
+	staged := func(v interface{}) (interface{}, error) {
+		mapped, err := fn(v)
+		if err != nil {
+			return nil, &Rejection{Stage: stage, Value: v, Err: err}
+		}
+		return mapped, nil
+	}
+	if it, ok := k.source(); ok {
+		logrus.Debug("  mapE: ", it)
+		return k.next(op, named, iterators.MapE(it, staged, k.reject()))
+	}
+	switch reflect.TypeOf(k.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		v, err := slices.MapE(reflect.ValueOf(k.wrapped), staged, k.reject())
+		logrus.Debug("  mapE: ", v)
+		if err != nil {
+			return k.fail(op, err)
+		}
+		return k.next(op, named, v)
+	}
+	return k.fail(op, UnsupportedWrappedTypeError)
+}
+
+// FilterE keeps the elements of `k` that predicate `p` is true for
+// an element `p` fails on is rejected in dead-letter mode, otherwise its
+// `*Rejection` becomes the chain's error
+// `opts` can retry failing elements and time out slow ones
+func (k *K) FilterE(p PredicateE, opts ...StageOption) Kundalini {
+	return k.filterE("FilterE", p, predicateOptions(k.serialPredicateE(p), opts))
+}
+
+// filterE is `FilterE` recorded in the lineage as `op` applying `named`
+func (k *K) filterE(op string, named interface{}, p PredicateE) Kundalini {
+	if k.err != nil {
+		return k
+	}
+	stage := k.stage
+	staged := func(v interface{}) (bool, error) {
+		ok, err := p(v)
+		if err != nil {
+			return false, &Rejection{Stage: stage, Value: v, Err: err}
+		}
+		return ok, nil
+	}
+	if it, ok := k.source(); ok {
+		logrus.Debug("filtrE: ", it)
+		return k.next(op, named, iterators.FilterE(it, staged, k.reject()))
+	}
+	switch reflect.TypeOf(k.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		v, err := slices.FilterE(reflect.ValueOf(k.wrapped), staged, k.reject())
+		logrus.Debug("filtrE: ", v)
+		if err != nil {
+			return k.fail(op, err)
+		}
+		return k.next(op, named, v)
+	}
+	return k.fail(op, UnsupportedWrappedTypeError)
+}
+
+// reject returns the callback that collects rejections in dead-letter mode
+// it is nil when `k` is not in dead-letter mode
+func (k *K) reject() func(interface{}, error) {
+	if k.letters == nil {
+		return nil
+	}
+	letters := k.letters
+	return func(_ interface{}, err error) {
+		logrus.Debug("reject: ", err)
+		letters.add(err.(*Rejection))
+	}
+}
+
+// recoverFn turns a panic in `fn` into an error
+func recoverFn(fn Fn) FnE {
+	return func(v interface{}) (mapped interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = panicError(r)
+			}
+		}()
+		return fn(v), nil
+	}
+}
+
+// recoverPredicate turns a panic in `p` into an error
+func recoverPredicate(p func(interface{}) bool) PredicateE {
+	return func(v interface{}) (ok bool, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = panicError(r)
+			}
+		}()
+		return p(v), nil
+	}
+}
+
+func panicError(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", r)
+}
@@ -0,0 +1,174 @@
+package kundalini_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+)
+
+var atoi = FnE(func(x interface{}) (interface{}, error) {
+	return strconv.Atoi(x.(string))
+})
+
+var errOdd = errors.New("odd")
+
+var even = PredicateE(func(x interface{}) (bool, error) {
+	if x.(int)%2 != 0 {
+		return false, errOdd
+	}
+	return true, nil
+})
+
+func TestMapE(t *testing.T) {
+
+	t.Run("should map elements when fn succeeds", func(t *testing.T) {
+		actual, err := Wrap([]interface{}{"1", "2"}).MapE(atoi).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{1, 2}, actual)
+	})
+
+	t.Run("should fail the chain with the stage that failed", func(t *testing.T) {
+		actual, err := Wrap([]interface{}{"1", "x"}).Push().MapE(atoi).Release()
+
+		assert.Nil(t, actual)
+		if r, ok := err.(*Rejection); assert.True(t, ok) {
+			assert.Equal(t, 1, r.Stage)
+			assert.Equal(t, "x", r.Value)
+		}
+	})
+
+	t.Run("should unwrap to the error of the stage", func(t *testing.T) {
+		_, err := Wrap([]int{2, 3}).FilterE(even).Release()
+
+		assert.True(t, errors.Is(err, errOdd))
+	})
+
+	t.Run("should reject failing elements in dead-letter mode", func(t *testing.T) {
+		k := Wrap([]interface{}{"1", "x", "3", ""}).DeadLetter().MapE(atoi)
+
+		actual, err := k.Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{1, 3}, actual)
+		rejected := k.Rejected()
+		if assert.Len(t, rejected, 2) {
+			assert.Equal(t, 0, rejected[0].Stage)
+			assert.Equal(t, "x", rejected[0].Value)
+			assert.Equal(t, "", rejected[1].Value)
+		}
+	})
+
+	t.Run("should reject lazily pulled elements", func(t *testing.T) {
+		k := Wrap(&counter{to: 5}).DeadLetter().FilterE(even)
+
+		actual, err := k.Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{0, 2, 4}, actual)
+		assert.Equal(t, []Rejection{{Stage: 0, Value: 1, Err: errOdd}, {Stage: 0, Value: 3, Err: errOdd}}, k.Rejected())
+	})
+
+	t.Run("should forward received error", func(t *testing.T) {
+		_, err := Wrap(0).Types().MapE(atoi).Release()
+
+		assert.Equal(t, UnsupportedWrappedTypeError, err)
+	})
+}
+
+func TestFilterE(t *testing.T) {
+
+	t.Run("should keep elements that p is true for", func(t *testing.T) {
+		actual, err := Wrap([]int{2, 4}).FilterE(even).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2, 4}, actual)
+	})
+
+	t.Run("should fail the chain when p fails", func(t *testing.T) {
+		_, err := Wrap([]int{2, 3}).FilterE(even).Release()
+
+		assert.EqualError(t, err, "stage 0: odd")
+	})
+
+	t.Run("should keep the element type in dead-letter mode", func(t *testing.T) {
+		actual, err := Wrap([]int{1, 2, 3, 4}).DeadLetter().FilterE(even).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2, 4}, actual)
+	})
+}
+
+func TestDeadLetter(t *testing.T) {
+	half := func(x interface{}) interface{} {
+		if x.(int)%2 != 0 {
+			panic(fmt.Sprintf("cannot halve %d", x))
+		}
+		return x.(int) / 2
+	}
+
+	t.Run("should reject elements a Fn panics on", func(t *testing.T) {
+		k := Wrap([]int{4, 5, 6}).DeadLetter().Map(half).Map(half)
+
+		actual, err := k.Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1}, actual)
+		rejected := k.Rejected()
+		if assert.Len(t, rejected, 2) {
+			assert.Equal(t, Rejection{Stage: 0, Value: 5, Err: errors.New("cannot halve 5")}, rejected[0])
+			assert.Equal(t, Rejection{Stage: 1, Value: 3, Err: errors.New("cannot halve 3")}, rejected[1])
+		}
+	})
+
+	t.Run("should reject elements a predicate panics on", func(t *testing.T) {
+		k := Wrap([]interface{}{1, "a", 2}).DeadLetter().Filter(func(x interface{}) bool { return x.(int) > 1 })
+
+		actual, err := k.Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{2}, actual)
+		assert.Len(t, k.Rejected(), 1)
+	})
+
+	t.Run("should reject invalid elements", func(t *testing.T) {
+		positive := Rule(func(e interface{}) error {
+			if e.(int) <= 0 {
+				return errOdd
+			}
+			return nil
+		})
+		k := Wrap([]int{1, -1}).DeadLetter().Validate(Rules{Checks: []Rule{positive}})
+
+		actual, err := k.Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1}, actual)
+		assert.Len(t, k.Rejected(), 1)
+	})
+
+	t.Run("should export rejected values to a buffer", func(t *testing.T) {
+		buf := []string{}
+		all := []Rejection{}
+
+		_, err := Wrap([]interface{}{"1", "x", "y"}).
+			DeadLetter().
+			MapE(atoi).
+			ExportRejected(reflect.ValueOf(&buf)).
+			ExportRejected(reflect.ValueOf(&all)).
+			Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"x", "y"}, buf)
+		assert.Len(t, all, 2)
+	})
+
+	t.Run("should report nothing outside dead-letter mode", func(t *testing.T) {
+		assert.Nil(t, Wrap([]int{2}).Map(half).Rejected())
+	})
+}
@@ -0,0 +1,872 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+var dynT = reflect.TypeOf((*interface{})(nil)).Elem()
+var boolT = reflect.TypeOf(false)
+var intT = reflect.TypeOf(0)
+var int64T = reflect.TypeOf(int64(0))
+var float64T = reflect.TypeOf(0.0)
+var stringT = reflect.TypeOf("")
+var byteT = reflect.TypeOf(byte(0))
+
+// constKind marks an operand as an untyped constant
+// untyped constants take the type of the operand they are combined with
+type constKind int
+
+const (
+	notConst constKind = iota
+	constBool
+	constInt
+	constFloat
+	constString
+	constNil
+)
+
+// operand is a compiled expression together with its static type
+// `t` is the empty interface type when the type is only known at runtime
+type operand struct {
+	t    reflect.Type
+	c    constKind
+	val  reflect.Value
+	eval func(*env) reflect.Value
+}
+
+// env binds the variables of an expression for a single evaluation
+type env struct {
+	x, acc reflect.Value
+}
+
+// scope holds the static types of the variables an expression may use
+type scope struct {
+	src    string
+	x, acc reflect.Type
+}
+
+func (s *scope) errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{Src: s.src, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// fail aborts an evaluation, it is recovered into a panic with the `*Error`
+func (s *scope) fail(pos int, err error) {
+	panic(s.errorf(pos, "%v", err))
+}
+
+// dynamic reports whether values of `t` are only typed at runtime
+func dynamic(t reflect.Type) bool {
+	return t.Kind() == reflect.Interface && t.NumMethod() == 0
+}
+
+// unwrap returns the dynamic value held by an interface
+// the zero `Value` stands for nil
+func unwrap(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func compile(n node, s *scope) (*operand, error) {
+	switch n := n.(type) {
+	case *identNode:
+		return compileIdent(n, s)
+	case *literalNode:
+		return compileLiteral(n, s)
+	case *unaryNode:
+		return compileUnary(n, s)
+	case *binaryNode:
+		return compileBinary(n, s)
+	case *selectorNode:
+		return compileSelector(n, s)
+	case *indexNode:
+		return compileIndex(n, s)
+	case *callNode:
+		return compileCall(n, s)
+	}
+	return nil, s.errorf(n.pos(), "unsupported expression")
+}
+
+func compileIdent(n *identNode, s *scope) (*operand, error) {
+	switch n.name {
+	case "x":
+		return &operand{t: s.x, eval: func(e *env) reflect.Value { return e.x }}, nil
+	case "acc":
+		if s.acc != nil {
+			return &operand{t: s.acc, eval: func(e *env) reflect.Value { return e.acc }}, nil
+		}
+	case "true", "false":
+		return &operand{c: constBool, val: reflect.ValueOf(n.name == "true")}, nil
+	case "nil":
+		return &operand{c: constNil}, nil
+	}
+	return nil, s.errorf(n.at, "undefined: %s", n.name)
+}
+
+func compileLiteral(n *literalNode, s *scope) (*operand, error) {
+	switch n.tok.kind {
+	case tokInt:
+		i, err := strconv.ParseInt(n.tok.text, 10, 64)
+		if err != nil {
+			return nil, s.errorf(n.at, "malformed integer %s", n.tok.text)
+		}
+		return &operand{c: constInt, val: reflect.ValueOf(i)}, nil
+	case tokFloat:
+		f, err := strconv.ParseFloat(n.tok.text, 64)
+		if err != nil {
+			return nil, s.errorf(n.at, "malformed float %s", n.tok.text)
+		}
+		return &operand{c: constFloat, val: reflect.ValueOf(f)}, nil
+	}
+	return &operand{c: constString, val: reflect.ValueOf(n.tok.text)}, nil
+}
+
+// constValue converts an untyped constant to a value of type `t`
+func constValue(c constKind, val reflect.Value, t reflect.Type) (reflect.Value, error) {
+	if dynamic(t) {
+		t = defaultType(c)
+	}
+	k := t.Kind()
+	switch c {
+	case constBool:
+		if k == reflect.Bool {
+			return val.Convert(t), nil
+		}
+	case constString:
+		if k == reflect.String {
+			return val.Convert(t), nil
+		}
+	case constInt, constFloat:
+		f := val.Convert(float64T).Float()
+		r := reflect.New(t).Elem()
+		switch {
+		case isInt(k) && f == math.Trunc(f):
+			n := int64(f)
+			if c == constInt {
+				n = val.Int()
+			}
+			if !r.OverflowInt(n) {
+				r.SetInt(n)
+				return r, nil
+			}
+		case isUint(k) && f == math.Trunc(f) && f >= 0:
+			n := uint64(f)
+			if c == constInt {
+				n = uint64(val.Int())
+			}
+			if !r.OverflowUint(n) {
+				r.SetUint(n)
+				return r, nil
+			}
+		case isFloat(k):
+			r.SetFloat(f)
+			return r, nil
+		}
+	case constNil:
+		if nillable(k) {
+			return reflect.Zero(t), nil
+		}
+	}
+	if c == constNil {
+		return reflect.Value{}, fmt.Errorf("cannot use nil as %s", t)
+	}
+	return reflect.Value{}, fmt.Errorf("cannot use %#v as %s", val, t)
+}
+
+// defaultType is the type an untyped constant takes when nothing else decides it
+func defaultType(c constKind) reflect.Type {
+	switch c {
+	case constBool:
+		return boolT
+	case constInt:
+		return intT
+	case constFloat:
+		return float64T
+	case constString:
+		return stringT
+	}
+	return dynT
+}
+
+// convert gives the untyped constant `o` the static type `t`
+func convert(o *operand, t reflect.Type, pos int, s *scope) (*operand, error) {
+	if o.c == notConst {
+		return o, nil
+	}
+	if o.c == constNil && dynamic(t) {
+		return &operand{t: t, eval: func(*env) reflect.Value { return reflect.Value{} }}, nil
+	}
+	v, err := constValue(o.c, o.val, t)
+	if err != nil {
+		return nil, s.errorf(pos, "%v", err)
+	}
+	return &operand{t: v.Type(), eval: func(*env) reflect.Value { return v }}, nil
+}
+
+// value evaluates `o`, converting untyped constants to `t`
+func (o *operand) value(e *env, t reflect.Type) (reflect.Value, error) {
+	if o.c == notConst {
+		return unwrap(o.eval(e)), nil
+	}
+	if o.c == constNil {
+		return reflect.Value{}, nil
+	}
+	return constValue(o.c, o.val, t)
+}
+
+func compileUnary(n *unaryNode, s *scope) (*operand, error) {
+	x, err := compile(n.x, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "!" {
+		x, err = asBool(x, n.x.pos(), s)
+		if err != nil {
+			return nil, err
+		}
+		return &operand{t: boolT, eval: func(e *env) reflect.Value {
+			return reflect.ValueOf(!s.truth(x.eval(e), n.x.pos()))
+		}}, nil
+	}
+
+	switch x.c {
+	case constInt:
+		return &operand{c: constInt, val: reflect.ValueOf(-x.val.Int())}, nil
+	case constFloat:
+		return &operand{c: constFloat, val: reflect.ValueOf(-x.val.Float())}, nil
+	case notConst:
+	default:
+		return nil, s.errorf(n.at, "operator - not defined on %v", x.val)
+	}
+	if !dynamic(x.t) && !allowed("-", x.t.Kind()) {
+		return nil, s.errorf(n.at, "operator - not defined on %s", x.t)
+	}
+	return &operand{t: x.t, eval: func(e *env) reflect.Value {
+		v := unwrap(x.eval(e))
+		if !v.IsValid() {
+			s.fail(n.at, fmt.Errorf("operator - not defined on nil"))
+		}
+		r, err := apply("-", reflect.Zero(v.Type()), v)
+		if err != nil {
+			s.fail(n.at, err)
+		}
+		return r
+	}}, nil
+}
+
+func asBool(o *operand, pos int, s *scope) (*operand, error) {
+	switch {
+	case o.c == constBool:
+		return convert(o, boolT, pos, s)
+	case o.c != notConst:
+		return nil, s.errorf(pos, "non-boolean %v used as condition", o.val)
+	case !dynamic(o.t) && o.t.Kind() != reflect.Bool:
+		return nil, s.errorf(pos, "non-boolean %s used as condition", o.t)
+	}
+	return o, nil
+}
+
+func (s *scope) truth(v reflect.Value, pos int) bool {
+	v = unwrap(v)
+	if !v.IsValid() || v.Kind() != reflect.Bool {
+		s.fail(pos, fmt.Errorf("non-boolean %v used as condition", v))
+	}
+	return v.Bool()
+}
+
+func compileBinary(n *binaryNode, s *scope) (*operand, error) {
+	l, err := compile(n.l, s)
+	if err != nil {
+		return nil, err
+	}
+	r, err := compile(n.r, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "&&" || n.op == "||" {
+		if l, err = asBool(l, n.l.pos(), s); err != nil {
+			return nil, err
+		}
+		if r, err = asBool(r, n.r.pos(), s); err != nil {
+			return nil, err
+		}
+		return &operand{t: boolT, eval: func(e *env) reflect.Value {
+			a := s.truth(l.eval(e), n.l.pos())
+			if (n.op == "&&") != a {
+				return reflect.ValueOf(a)
+			}
+			return reflect.ValueOf(s.truth(r.eval(e), n.r.pos()))
+		}}, nil
+	}
+
+	if l.c != notConst && r.c != notConst {
+		return foldBinary(n, l, r, s)
+	}
+	if (n.op == "/" || n.op == "%") && (r.c == constInt || r.c == constFloat) && r.val.IsZero() {
+		return nil, s.errorf(n.at, "division by zero")
+	}
+
+	cmp := comparison(n.op)
+	if l.c == constNil || r.c == constNil {
+		other := l
+		if l.c == constNil {
+			other = r
+		}
+		if n.op != "==" && n.op != "!=" {
+			return nil, s.errorf(n.at, "operator %s not defined on nil", n.op)
+		}
+		if !dynamic(other.t) && !nillable(other.t.Kind()) {
+			return nil, s.errorf(n.at, "cannot compare %s to nil", other.t)
+		}
+		return &operand{t: boolT, eval: func(e *env) reflect.Value {
+			v := unwrap(other.eval(e))
+			isNil := !v.IsValid() || nillable(v.Kind()) && v.IsNil()
+			return reflect.ValueOf(isNil == (n.op == "=="))
+		}}, nil
+	}
+
+	if r.c == notConst && !dynamic(r.t) {
+		if l, err = convert(l, r.t, n.l.pos(), s); err != nil {
+			return nil, err
+		}
+	}
+	if l.c == notConst && !dynamic(l.t) {
+		if r, err = convert(r, l.t, n.r.pos(), s); err != nil {
+			return nil, err
+		}
+	}
+
+	t := dynT
+	for _, o := range []*operand{l, r} {
+		if o.c == notConst && !dynamic(o.t) {
+			if !allowed(n.op, o.t.Kind()) || cmp && !o.t.Comparable() {
+				return nil, s.errorf(n.at, "operator %s not defined on %s", n.op, o.t)
+			}
+			t = o.t
+		}
+	}
+	if l.c == notConst && r.c == notConst && !dynamic(l.t) && !dynamic(r.t) && l.t != r.t {
+		return nil, s.errorf(n.at, "mismatched types %s and %s", l.t, r.t)
+	}
+	if cmp {
+		t = boolT
+	}
+
+	return &operand{t: t, eval: func(e *env) reflect.Value {
+		var a, b reflect.Value
+		var err error
+		switch {
+		case l.c != notConst:
+			b = unwrap(r.eval(e))
+			a, err = l.value(e, typeOf(b))
+		case r.c != notConst:
+			a = unwrap(l.eval(e))
+			b, err = r.value(e, typeOf(a))
+		default:
+			a, b = unwrap(l.eval(e)), unwrap(r.eval(e))
+		}
+		if err != nil {
+			s.fail(n.at, err)
+		}
+		v, err := apply(n.op, a, b)
+		if err != nil {
+			s.fail(n.at, err)
+		}
+		return v
+	}}, nil
+}
+
+// foldBinary evaluates an operator on two untyped constants
+func foldBinary(n *binaryNode, l, r *operand, s *scope) (*operand, error) {
+	if l.c == constNil || r.c == constNil {
+		return nil, s.errorf(n.at, "operator %s not defined on nil", n.op)
+	}
+	c := l.c
+	if l.c == constFloat || r.c == constFloat {
+		if (l.c == constInt || l.c == constFloat) && (r.c == constInt || r.c == constFloat) {
+			c = constFloat
+		}
+	}
+	t := defaultType(c)
+	a, err := constValue(l.c, l.val, t)
+	if err != nil {
+		return nil, s.errorf(n.at, "mismatched constants %v and %v", l.val, r.val)
+	}
+	b, err := constValue(r.c, r.val, t)
+	if err != nil {
+		return nil, s.errorf(n.at, "mismatched constants %v and %v", l.val, r.val)
+	}
+	if !allowed(n.op, t.Kind()) {
+		return nil, s.errorf(n.at, "operator %s not defined on %v", n.op, l.val)
+	}
+	v, err := apply(n.op, a, b)
+	if err != nil {
+		return nil, s.errorf(n.at, "%v", err)
+	}
+	if comparison(n.op) {
+		return &operand{c: constBool, val: v}, nil
+	}
+	if c == constInt {
+		v = v.Convert(int64T)
+	}
+	return &operand{c: c, val: v}, nil
+}
+
+func compileSelector(n *selectorNode, s *scope) (*operand, error) {
+	x, err := compile(n.x, s)
+	if err != nil {
+		return nil, err
+	}
+	if x.c != notConst {
+		return nil, s.errorf(n.at, "cannot select %s from a constant", n.name)
+	}
+
+	if dynamic(x.t) {
+		return &operand{t: dynT, eval: func(e *env) reflect.Value {
+			v := unwrap(x.eval(e))
+			if !v.IsValid() {
+				s.fail(n.at, fmt.Errorf("cannot select %s from nil", n.name))
+			}
+			get, _, err := selector(v.Type(), n.name)
+			if err != nil {
+				s.fail(n.at, err)
+			}
+			r, err := get(v)
+			if err != nil {
+				s.fail(n.at, err)
+			}
+			return r
+		}}, nil
+	}
+
+	get, t, err := selector(x.t, n.name)
+	if err != nil {
+		return nil, s.errorf(n.at, "%v", err)
+	}
+	return &operand{t: t, eval: func(e *env) reflect.Value {
+		r, err := get(x.eval(e))
+		if err != nil {
+			s.fail(n.at, err)
+		}
+		return r
+	}}, nil
+}
+
+// selector resolves `name` to an exported struct field or a string map key of `t`
+func selector(t reflect.Type, name string) (func(reflect.Value) (reflect.Value, error), reflect.Type, error) {
+	if _, ok := t.MethodByName(name); ok {
+		return nil, nil, fmt.Errorf("%s is a method of %s, call it as %s()", name, t, name)
+	}
+
+	base := t
+	if base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+	deref := func(v reflect.Value) (reflect.Value, error) {
+		v = unwrap(v)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return v, fmt.Errorf("cannot select %s from nil %s", name, t)
+			}
+			v = v.Elem()
+		}
+		return v, nil
+	}
+
+	switch base.Kind() {
+	case reflect.Struct:
+		f, ok := base.FieldByName(name)
+		if ok && f.PkgPath != "" {
+			return nil, nil, fmt.Errorf("%s is an unexported field of %s", name, t)
+		}
+		if ok {
+			return func(v reflect.Value) (reflect.Value, error) {
+				v, err := deref(v)
+				if err != nil {
+					return v, err
+				}
+				return v.FieldByIndexErr(f.Index)
+			}, f.Type, nil
+		}
+	case reflect.Map:
+		if base.Key().Kind() == reflect.String {
+			key := reflect.ValueOf(name).Convert(base.Key())
+			return func(v reflect.Value) (reflect.Value, error) {
+				v, err := deref(v)
+				if err != nil {
+					return v, err
+				}
+				if r := v.MapIndex(key); r.IsValid() {
+					return r, nil
+				}
+				return reflect.Zero(base.Elem()), nil
+			}, base.Elem(), nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%s undefined (type %s has no field or method %s)", name, t, name)
+}
+
+func compileIndex(n *indexNode, s *scope) (*operand, error) {
+	x, err := compile(n.x, s)
+	if err != nil {
+		return nil, err
+	}
+	if x.c != notConst {
+		return nil, s.errorf(n.at, "cannot index a constant")
+	}
+	idx, err := compile(n.index, s)
+	if err != nil {
+		return nil, err
+	}
+
+	t := dynT
+	if !dynamic(x.t) {
+		base := x.t
+		if base.Kind() == reflect.Ptr && base.Elem().Kind() == reflect.Array {
+			base = base.Elem()
+		}
+		var key reflect.Type
+		switch base.Kind() {
+		case reflect.Slice, reflect.Array:
+			key, t = intT, base.Elem()
+		case reflect.String:
+			key, t = intT, byteT
+		case reflect.Map:
+			key, t = base.Key(), base.Elem()
+		default:
+			return nil, s.errorf(n.at, "cannot index %s", x.t)
+		}
+		if idx.c != notConst {
+			if _, err := convert(idx, key, n.index.pos(), s); err != nil {
+				return nil, err
+			}
+		} else if !dynamic(idx.t) {
+			if base.Kind() == reflect.Map && !idx.t.AssignableTo(key) || base.Kind() != reflect.Map && !isInt(idx.t.Kind()) && !isUint(idx.t.Kind()) {
+				return nil, s.errorf(n.index.pos(), "cannot use %s as index of %s", idx.t, x.t)
+			}
+		}
+	}
+
+	return &operand{t: t, eval: func(e *env) reflect.Value {
+		r, err := index(unwrap(x.eval(e)), idx, e)
+		if err != nil {
+			s.fail(n.at, err)
+		}
+		return r
+	}}, nil
+}
+
+// index looks up `idx` in the slice, array, string or map `v`
+func index(v reflect.Value, idx *operand, e *env) (reflect.Value, error) {
+	if !v.IsValid() {
+		return v, fmt.Errorf("cannot index nil")
+	}
+	if v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Array {
+		if v.IsNil() {
+			return v, fmt.Errorf("cannot index nil %s", v.Type())
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.String:
+		i, err := idx.value(e, intT)
+		if err != nil {
+			return v, err
+		}
+		var n int64
+		switch {
+		case i.IsValid() && isInt(i.Kind()):
+			n = i.Int()
+		case i.IsValid() && isUint(i.Kind()):
+			n = int64(i.Uint())
+		default:
+			return v, fmt.Errorf("cannot use %v as index of %s", i, v.Type())
+		}
+		if n < 0 || n >= int64(v.Len()) {
+			return v, fmt.Errorf("index %d out of range [0:%d]", n, v.Len())
+		}
+		return v.Index(int(n)), nil
+	case reflect.Map:
+		key := v.Type().Key()
+		k, err := idx.value(e, key)
+		if err != nil {
+			return v, err
+		}
+		if !k.IsValid() {
+			k = reflect.Zero(key)
+		}
+		if !k.Type().AssignableTo(key) {
+			return v, fmt.Errorf("cannot use %s as index of %s", k.Type(), v.Type())
+		}
+		if r := v.MapIndex(k); r.IsValid() {
+			return r, nil
+		}
+		return reflect.Zero(v.Type().Elem()), nil
+	}
+	return v, fmt.Errorf("cannot index %s", v.Type())
+}
+
+func compileCall(n *callNode, s *scope) (*operand, error) {
+	sel, ok := n.fn.(*selectorNode)
+	if !ok {
+		return nil, s.errorf(n.at, "only methods can be called")
+	}
+	x, err := compile(sel.x, s)
+	if err != nil {
+		return nil, err
+	}
+	if x.c != notConst {
+		return nil, s.errorf(sel.at, "cannot call %s on a constant", sel.name)
+	}
+	args := make([]*operand, len(n.args))
+	for i, a := range n.args {
+		if args[i], err = compile(a, s); err != nil {
+			return nil, err
+		}
+	}
+
+	t := dynT
+	if !dynamic(x.t) {
+		m, ok := x.t.MethodByName(sel.name)
+		if !ok {
+			if x.t.Kind() != reflect.Ptr && x.t.Kind() != reflect.Interface {
+				if _, ok := reflect.PtrTo(x.t).MethodByName(sel.name); ok {
+					return nil, s.errorf(sel.at, "method %s of %s has a pointer receiver", sel.name, x.t)
+				}
+			}
+			return nil, s.errorf(sel.at, "%s undefined (type %s has no method %s)", sel.name, x.t, sel.name)
+		}
+		mt := m.Type
+		offset := 1
+		if x.t.Kind() == reflect.Interface {
+			offset = 0
+		}
+		if mt.IsVariadic() || mt.NumIn()-offset != len(args) {
+			return nil, s.errorf(n.at, "wrong number of arguments to %s", sel.name)
+		}
+		if mt.NumOut() != 1 {
+			return nil, s.errorf(n.at, "%s must return a single value", sel.name)
+		}
+		for i, a := range args {
+			p := mt.In(i + offset)
+			if a.c != notConst {
+				if _, err := convert(a, p, n.args[i].pos(), s); err != nil {
+					return nil, err
+				}
+			} else if !dynamic(a.t) && !a.t.AssignableTo(p) {
+				return nil, s.errorf(n.args[i].pos(), "cannot use %s as %s in argument to %s", a.t, p, sel.name)
+			}
+		}
+		t = mt.Out(0)
+	}
+
+	return &operand{t: t, eval: func(e *env) reflect.Value {
+		r, err := call(x.eval(e), sel.name, args, e)
+		if err != nil {
+			s.fail(n.at, err)
+		}
+		return r
+	}}, nil
+}
+
+// call invokes the method `name` of `v` with `args`
+func call(v reflect.Value, name string, args []*operand, e *env) (reflect.Value, error) {
+	if v.Kind() == reflect.Interface {
+		v = unwrap(v)
+	}
+	if !v.IsValid() {
+		return v, fmt.Errorf("cannot call %s on nil", name)
+	}
+	m := v.MethodByName(name)
+	if !m.IsValid() {
+		return v, fmt.Errorf("%s undefined (type %s has no method %s)", name, v.Type(), name)
+	}
+	mt := m.Type()
+	if mt.IsVariadic() || mt.NumIn() != len(args) || mt.NumOut() != 1 {
+		return v, fmt.Errorf("%s of %s cannot be called with %d arguments", name, v.Type(), len(args))
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, a := range args {
+		p := mt.In(i)
+		arg, err := a.value(e, p)
+		if err != nil {
+			return v, err
+		}
+		if !arg.IsValid() {
+			arg = reflect.Zero(p)
+		}
+		if !arg.Type().AssignableTo(p) {
+			return v, fmt.Errorf("cannot use %s as %s in argument to %s", arg.Type(), p, name)
+		}
+		in[i] = arg
+	}
+	return m.Call(in)[0], nil
+}
+
+// apply evaluates a binary operator on two values of the same type
+// the zero `Value` stands for nil
+func apply(op string, a, b reflect.Value) (reflect.Value, error) {
+	if !a.IsValid() || !b.IsValid() {
+		return reflect.Value{}, fmt.Errorf("operator %s not defined on nil", op)
+	}
+	if a.Type() != b.Type() {
+		return reflect.Value{}, fmt.Errorf("mismatched types %s and %s", a.Type(), b.Type())
+	}
+	t := a.Type()
+	k := t.Kind()
+	if !allowed(op, k) || comparison(op) && !t.Comparable() {
+		return reflect.Value{}, fmt.Errorf("operator %s not defined on %s", op, t)
+	}
+
+	switch op {
+	case "==":
+		return reflect.ValueOf(a.Interface() == b.Interface()), nil
+	case "!=":
+		return reflect.ValueOf(a.Interface() != b.Interface()), nil
+	case "<", "<=", ">", ">=":
+		var c int
+		switch {
+		case isInt(k):
+			c = compare(a.Int() < b.Int(), a.Int() > b.Int())
+		case isUint(k):
+			c = compare(a.Uint() < b.Uint(), a.Uint() > b.Uint())
+		case isFloat(k):
+			c = compare(a.Float() < b.Float(), a.Float() > b.Float())
+		default:
+			c = compare(a.String() < b.String(), a.String() > b.String())
+		}
+		switch op {
+		case "<":
+			return reflect.ValueOf(c < 0), nil
+		case "<=":
+			return reflect.ValueOf(c <= 0), nil
+		case ">":
+			return reflect.ValueOf(c > 0), nil
+		}
+		return reflect.ValueOf(c >= 0), nil
+	}
+
+	r := reflect.New(t).Elem()
+	switch {
+	case isInt(k):
+		x, y := a.Int(), b.Int()
+		if (op == "/" || op == "%") && y == 0 {
+			return r, fmt.Errorf("integer division by zero")
+		}
+		switch op {
+		case "+":
+			r.SetInt(x + y)
+		case "-":
+			r.SetInt(x - y)
+		case "*":
+			r.SetInt(x * y)
+		case "/":
+			r.SetInt(x / y)
+		case "%":
+			r.SetInt(x % y)
+		}
+	case isUint(k):
+		x, y := a.Uint(), b.Uint()
+		if (op == "/" || op == "%") && y == 0 {
+			return r, fmt.Errorf("integer division by zero")
+		}
+		switch op {
+		case "+":
+			r.SetUint(x + y)
+		case "-":
+			r.SetUint(x - y)
+		case "*":
+			r.SetUint(x * y)
+		case "/":
+			r.SetUint(x / y)
+		case "%":
+			r.SetUint(x % y)
+		}
+	case isFloat(k):
+		x, y := a.Float(), b.Float()
+		switch op {
+		case "+":
+			r.SetFloat(x + y)
+		case "-":
+			r.SetFloat(x - y)
+		case "*":
+			r.SetFloat(x * y)
+		case "/":
+			r.SetFloat(x / y)
+		}
+	default:
+		r.SetString(a.String() + b.String())
+	}
+	return r, nil
+}
+
+func compare(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+func comparison(op string) bool {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// allowed reports whether `op` is defined on values of kind `k`
+func allowed(op string, k reflect.Kind) bool {
+	switch op {
+	case "==", "!=":
+		return true
+	case "<", "<=", ">", ">=", "+":
+		return isInt(k) || isUint(k) || isFloat(k) || k == reflect.String
+	case "-", "*", "/":
+		return isInt(k) || isUint(k) || isFloat(k)
+	case "%":
+		return isInt(k) || isUint(k)
+	}
+	return false
+}
+
+func typeOf(v reflect.Value) reflect.Type {
+	if !v.IsValid() {
+		return dynT
+	}
+	return v.Type()
+}
+
+func isInt(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUint(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func nillable(k reflect.Kind) bool {
+	switch k {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return true
+	}
+	return false
+}
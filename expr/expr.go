@@ -0,0 +1,153 @@
+// Package expr compiles small expressions into kundalini `Predicate`s, `Fn`s
+// and `Transform`s
+//
+// expressions refer to the current element as `x` and, in a `Transform`, to
+// the accumulator as `acc`
+//
+//	x.Age > 30 && x.Country == "NZ"
+//	x.Initials() + x.Tags["team"]
+//	acc + x.Items[0].Price * 2
+//
+// struct fields, string map keys and methods are reached with `.`, slices,
+// arrays, strings and maps are indexed with `[]`
+// operators and untyped constants follow Go's rules, so the operands of a
+// binary operator must have identical types
+//
+// types are checked when an expression is compiled against a prototype
+// element, passing a nil prototype or reaching an `interface{}` value defers
+// the checks to evaluation, where failures panic with an `*Error`
+package expr
+
+import (
+	"fmt"
+	"reflect"
+
+	"gitlab.com/jdbellamy/kundalini"
+)
+
+// Error reports a problem found while compiling or evaluating an expression
+type Error struct {
+	Src string
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%q at %d: %s", e.Src, e.Pos, e.Msg)
+}
+
+// Predicate compiles `src` into a `Predicate` over elements typed like `x`
+func Predicate(src string, x interface{}) (kundalini.Predicate, error) {
+	s := &scope{src: src, x: protoType(x)}
+	o, err := compileSource(s)
+	if err != nil {
+		return nil, err
+	}
+	if o, err = asBool(o, 0, s); err != nil {
+		return nil, err
+	}
+
+	return func(e interface{}) bool {
+		return s.truth(o.eval(s.bind(e, nil)), 0)
+	}, nil
+}
+
+// Fn compiles `src` into a `Fn` over elements typed like `x`
+func Fn(src string, x interface{}) (kundalini.Fn, error) {
+	s := &scope{src: src, x: protoType(x)}
+	o, err := compileSource(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(e interface{}) interface{} {
+		return result(o.eval(s.bind(e, nil)))
+	}, nil
+}
+
+// Transform compiles `src` into a `Transform` with an accumulator typed like
+// `acc` over elements typed like `x`
+func Transform(src string, acc interface{}, x interface{}) (kundalini.Transform, error) {
+	s := &scope{src: src, x: protoType(x), acc: protoType(acc)}
+	o, err := compileSource(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(a interface{}, e interface{}) interface{} {
+		return result(o.eval(s.bind(e, a)))
+	}, nil
+}
+
+// MustPredicate is like `Predicate` but panics if `src` does not compile
+func MustPredicate(src string, x interface{}) kundalini.Predicate {
+	p, err := Predicate(src, x)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// MustFn is like `Fn` but panics if `src` does not compile
+func MustFn(src string, x interface{}) kundalini.Fn {
+	fn, err := Fn(src, x)
+	if err != nil {
+		panic(err)
+	}
+	return fn
+}
+
+// MustTransform is like `Transform` but panics if `src` does not compile
+func MustTransform(src string, acc interface{}, x interface{}) kundalini.Transform {
+	fn, err := Transform(src, acc, x)
+	if err != nil {
+		panic(err)
+	}
+	return fn
+}
+
+func compileSource(s *scope) (*operand, error) {
+	n, err := parse(s.src)
+	if err != nil {
+		err.(*Error).Src = s.src
+		return nil, err
+	}
+	o, err := compile(n, s)
+	if err != nil {
+		return nil, err
+	}
+	if o.c != notConst {
+		return convert(o, defaultType(o.c), 0, s)
+	}
+	return o, nil
+}
+
+func protoType(proto interface{}) reflect.Type {
+	if proto == nil {
+		return dynT
+	}
+	return reflect.TypeOf(proto)
+}
+
+// bind checks that `x` and `acc` have the types the expression was compiled for
+func (s *scope) bind(x interface{}, acc interface{}) *env {
+	e := &env{x: reflect.ValueOf(x)}
+	if !dynamic(s.x) && typeOf(e.x) != s.x {
+		s.fail(0, fmt.Errorf("element %v is not a %s", x, s.x))
+	}
+	if s.acc != nil {
+		e.acc = reflect.ValueOf(acc)
+		if !dynamic(s.acc) && typeOf(e.acc) != s.acc {
+			s.fail(0, fmt.Errorf("accumulator %v is not a %s", acc, s.acc))
+		}
+	}
+	return e
+}
+
+func result(v reflect.Value) interface{} {
+	v = unwrap(v)
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
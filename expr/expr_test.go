@@ -0,0 +1,243 @@
+package expr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+	"gitlab.com/jdbellamy/kundalini/expr"
+)
+
+type Address struct {
+	City string
+}
+
+type Person struct {
+	Name    string
+	Age     int
+	Country string
+	Score   float64
+	Tags    map[string]string
+	Home    *Address
+	secret  string
+}
+
+func (p Person) Initials() string {
+	return p.Name[:1]
+}
+
+func (p Person) Older(n int) bool {
+	return p.Age > n
+}
+
+var people = []Person{
+	{Name: "Ana", Age: 34, Country: "NZ", Score: 1.5, Home: &Address{City: "Wellington"}},
+	{Name: "Bo", Age: 29, Country: "NZ", Score: 2, Tags: map[string]string{"team": "red"}},
+	{Name: "Cy", Age: 41, Country: "AU", Score: 0.5, Home: &Address{City: "Perth"}},
+}
+
+func TestPredicate(t *testing.T) {
+
+	t.Run("should filter elements correctly", func(t *testing.T) {
+		type Test struct {
+			src      string
+			expected []string
+		}
+
+		tests := []Test{{
+			src:      `x.Age > 30 && x.Country == "NZ"`,
+			expected: []string{"Ana"},
+		}, {
+			src:      `x.Age > 30 || x.Country == "NZ"`,
+			expected: []string{"Ana", "Bo", "Cy"},
+		}, {
+			src:      `!(x.Score >= 1) || x.Age % 2 == 1`,
+			expected: []string{"Bo", "Cy"},
+		}, {
+			src:      `x.Home != nil && x.Home.City == "Perth"`,
+			expected: []string{"Cy"},
+		}, {
+			src:      `x.Tags["team"] == "red"`,
+			expected: []string{"Bo"},
+		}, {
+			src:      `x.Tags.team == "red"`,
+			expected: []string{"Bo"},
+		}, {
+			src:      `x.Initials() == "C" || x.Older(33) && x.Name[0] == 65`,
+			expected: []string{"Ana", "Cy"},
+		}, {
+			src:      `x.Score * 2 > 3.5 - 0.5`,
+			expected: []string{"Bo"},
+		}}
+
+		for _, tt := range tests {
+			p, err := expr.Predicate(tt.src, Person{})
+			assert.NoError(t, err, tt.src)
+
+			actual, err := Wrap(people).
+				Filter(p).
+				Map(func(x interface{}) interface{} { return Person{Name: x.(Person).Name} }).
+				Release()
+
+			names := []string{}
+			for _, p := range actual.([]Person) {
+				names = append(names, p.Name)
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, names, tt.src)
+		}
+	})
+
+	t.Run("should report type errors when compiled", func(t *testing.T) {
+		type Test struct {
+			src string
+			msg string
+		}
+
+		tests := []Test{{
+			src: `x.Age > "30"`,
+			msg: `cannot use "30" as int`,
+		}, {
+			src: `x.Age > 30.5`,
+			msg: "cannot use 30.5 as int",
+		}, {
+			src: `x.Age == x.Score`,
+			msg: "mismatched types int and float64",
+		}, {
+			src: `x.Height > 2`,
+			msg: "Height undefined (type expr_test.Person has no field or method Height)",
+		}, {
+			src: `x.secret == ""`,
+			msg: "secret is an unexported field of expr_test.Person",
+		}, {
+			src: `x.Initials == "A"`,
+			msg: "Initials is a method of expr_test.Person, call it as Initials()",
+		}, {
+			src: `x.Older("a")`,
+			msg: `cannot use "a" as int`,
+		}, {
+			src: `x.Older()`,
+			msg: "wrong number of arguments to Older",
+		}, {
+			src: `x.Age`,
+			msg: "non-boolean int used as condition",
+		}, {
+			src: `x.Name - "a" == ""`,
+			msg: "operator - not defined on string",
+		}, {
+			src: `x.Age == nil`,
+			msg: "cannot compare int to nil",
+		}, {
+			src: `x.Age / 0 > 1`,
+			msg: "division by zero",
+		}, {
+			src: `x.Score / 0.0 > 1`,
+			msg: "division by zero",
+		}, {
+			src: `x.Age % 0 == 1`,
+			msg: "division by zero",
+		}, {
+			src: `y > 1`,
+			msg: "undefined: y",
+		}, {
+			src: `x.Age >`,
+			msg: "unexpected end of expression",
+		}, {
+			src: `x.Age > 1 )`,
+			msg: `unexpected ")"`,
+		}}
+
+		for _, tt := range tests {
+			p, err := expr.Predicate(tt.src, Person{})
+
+			assert.Nil(t, p)
+			if assert.Error(t, err, tt.src) {
+				assert.IsType(t, &expr.Error{}, err)
+				assert.True(t, strings.HasSuffix(err.Error(), tt.msg), err.Error())
+			}
+		}
+	})
+
+	t.Run("should check interface values when evaluated", func(t *testing.T) {
+		v := []interface{}{
+			map[string]interface{}{"n": 1},
+			map[string]interface{}{"n": 5},
+			map[string]interface{}{"n": "five"},
+		}
+		p, err := expr.Predicate(`x.n > 2`, nil)
+		assert.NoError(t, err)
+
+		assert.False(t, p(v[0]))
+		assert.True(t, p(v[1]))
+		assert.Panics(t, func() { p(v[2]) })
+	})
+}
+
+func TestFn(t *testing.T) {
+
+	t.Run("should map elements correctly", func(t *testing.T) {
+		fn, err := expr.Fn(`x.Age * 2 + 1`, Person{})
+		assert.NoError(t, err)
+
+		actual := []interface{}{}
+		for _, p := range people {
+			actual = append(actual, fn(p))
+		}
+
+		assert.Equal(t, []interface{}{69, 59, 83}, actual)
+	})
+
+	t.Run("should call methods and index maps", func(t *testing.T) {
+		fn, err := expr.Fn(`x.Initials() + x.Tags["team"]`, Person{})
+		assert.NoError(t, err)
+
+		actual := []interface{}{}
+		for _, p := range people {
+			actual = append(actual, fn(p))
+		}
+
+		assert.Equal(t, []interface{}{"A", "Bred", "C"}, actual)
+	})
+
+	t.Run("should keep the element type of the expression", func(t *testing.T) {
+		fn, err := expr.Fn(`x * 3`, int8(0))
+		assert.NoError(t, err)
+
+		actual, err := Wrap([]int8{1, 2, 50}).Map(fn).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int8{3, 6, -106}, actual)
+	})
+
+	t.Run("should panic with an *Error when evaluation fails", func(t *testing.T) {
+		fn, err := expr.Fn(`x.Home.City`, Person{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "Wellington", fn(people[0]))
+		defer func() {
+			r := recover()
+			assert.IsType(t, &expr.Error{}, r)
+		}()
+		fn(people[1])
+	})
+}
+
+func TestTransform(t *testing.T) {
+
+	t.Run("should accumulate correctly", func(t *testing.T) {
+		sum := expr.MustTransform(`acc + x.Age`, 0, Person{})
+
+		actual, err := Wrap(people).Reduce(0, sum).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{104}, actual)
+	})
+
+	t.Run("should not allow acc outside of a Transform", func(t *testing.T) {
+		_, err := expr.Fn(`acc + x`, 0)
+
+		assert.EqualError(t, err, `"acc + x" at 0: undefined: acc`)
+	})
+}
@@ -0,0 +1,308 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokInt
+	tokFloat
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits `src` into tokens
+func lex(src string) ([]token, error) {
+	toks := make([]token, 0)
+	i := 0
+	for i < len(src) {
+		r, w := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += w
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(src) {
+				r, w = utf8.DecodeRuneInString(src[i:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += w
+			}
+			toks = append(toks, token{tokIdent, src[start:i], start})
+		case r >= '0' && r <= '9':
+			start := i
+			kind := tokInt
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.') {
+				if src[i] == '.' {
+					if kind == tokFloat {
+						return nil, &Error{Pos: i, Msg: "malformed number"}
+					}
+					kind = tokFloat
+				}
+				i++
+			}
+			toks = append(toks, token{kind, src[start:i], start})
+		case r == '"' || r == '`':
+			start := i
+			i++
+			for i < len(src) && rune(src[i]) != r {
+				if src[i] == '\\' && r == '"' {
+					i++
+				}
+				i++
+			}
+			if i >= len(src) {
+				return nil, &Error{Pos: start, Msg: "unterminated string"}
+			}
+			i++
+			s, err := strconv.Unquote(src[start:i])
+			if err != nil {
+				return nil, &Error{Pos: start, Msg: "malformed string"}
+			}
+			toks = append(toks, token{tokString, s, start})
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">="} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" && strings.ContainsRune("()[].,!<>+-*/%", r) {
+				op = string(r)
+			}
+			if op == "" {
+				return nil, &Error{Pos: i, Msg: fmt.Sprintf("unexpected %q", r)}
+			}
+			toks = append(toks, token{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(toks, token{tokEOF, "", len(src)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// node is an element of the syntax tree produced by `parse`
+type node interface {
+	pos() int
+}
+
+type (
+	identNode struct {
+		at   int
+		name string
+	}
+	literalNode struct {
+		at  int
+		tok token
+	}
+	unaryNode struct {
+		at int
+		op string
+		x  node
+	}
+	binaryNode struct {
+		at   int
+		op   string
+		l, r node
+	}
+	selectorNode struct {
+		at   int
+		x    node
+		name string
+	}
+	indexNode struct {
+		at       int
+		x, index node
+	}
+	callNode struct {
+		at   int
+		fn   node
+		args []node
+	}
+)
+
+func (n *identNode) pos() int    { return n.at }
+func (n *literalNode) pos() int  { return n.at }
+func (n *unaryNode) pos() int    { return n.at }
+func (n *binaryNode) pos() int   { return n.at }
+func (n *selectorNode) pos() int { return n.at }
+func (n *indexNode) pos() int    { return n.at }
+func (n *callNode) pos() int     { return n.at }
+
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5, "%": 5,
+}
+
+type parser struct {
+	toks []token
+	i    int
+}
+
+// parse builds the syntax tree for `src`
+func parse(src string) (node, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	n, err := p.binary(1)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.text)}
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) expect(op string) error {
+	t := p.next()
+	if t.kind != tokOp || t.text != op {
+		return &Error{Pos: t.pos, Msg: fmt.Sprintf("expected %q", op)}
+	}
+	return nil
+}
+
+func (p *parser) binary(min int) (node, error) {
+	l, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		prec, ok := precedence[t.text]
+		if t.kind != tokOp || !ok || prec < min {
+			return l, nil
+		}
+		p.next()
+		r, err := p.binary(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{at: t.pos, op: t.text, l: l, r: r}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	t := p.peek()
+	if t.kind == tokOp && (t.text == "!" || t.text == "-") {
+		p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{at: t.pos, op: t.text, x: x}, nil
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() (node, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOp {
+			return x, nil
+		}
+		switch t.text {
+		case ".":
+			p.next()
+			name := p.next()
+			if name.kind != tokIdent {
+				return nil, &Error{Pos: name.pos, Msg: "expected field or method name"}
+			}
+			x = &selectorNode{at: name.pos, x: x, name: name.text}
+		case "[":
+			p.next()
+			index, err := p.binary(1)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			x = &indexNode{at: t.pos, x: x, index: index}
+		case "(":
+			p.next()
+			args := make([]node, 0)
+			for p.peek().text != ")" {
+				arg, err := p.binary(1)
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if p.peek().text != "," {
+					break
+				}
+				p.next()
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			x = &callNode{at: t.pos, fn: x, args: args}
+		default:
+			return x, nil
+		}
+	}
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokIdent:
+		return &identNode{at: t.pos, name: t.text}, nil
+	case tokInt, tokFloat, tokString:
+		return &literalNode{at: t.pos, tok: t}, nil
+	case tokOp:
+		if t.text == "(" {
+			x, err := p.binary(1)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return x, nil
+		}
+	case tokEOF:
+		return nil, &Error{Pos: t.pos, Msg: "unexpected end of expression"}
+	}
+	return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.text)}
+}
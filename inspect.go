@@ -0,0 +1,113 @@
+package kundalini
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/slices"
+)
+
+// InspectMaxElems is the number of elements `Inspect` prints for each sequence
+var InspectMaxElems = 8
+
+// InspectMaxWidth is the number of characters `Inspect` prints for each element
+var InspectMaxWidth = 60
+
+// Inspect writes a readable dump of the value wrapped by `k`, a summary of
+// its element types and the contents of the stack to `w`
+// `k` is returned unchanged, a lazy `k` is collected first so its elements
+// are dumped and still reach the stages that follow
+func (k *K) Inspect(w io.Writer) Kundalini {
+	k = k.drain()
+	b := &strings.Builder{}
+	if k.err != nil {
+		fmt.Fprintf(b, "error: %v\n", k.err)
+	} else {
+		fmt.Fprintf(b, "value: %s\n", dump(k.wrapped))
+		fmt.Fprintf(b, "types: %s\n", typeSummary(k.wrapped))
+	}
+	fmt.Fprintf(b, "stack: %d\n", k.stack.len())
+	for f := k.stack; f != nil; f = f.below {
+		fmt.Fprintf(b, "  [%d] %s\n", f.depth-1, dump(f.value))
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		logrus.Debug("inspect: ", err)
+	}
+	return k
+}
+
+// Tap calls `fn` with the value wrapped by `k` and returns `k` unchanged
+// a lazy `k` is collected first, `fn` is given the slice of its elements
+func (k *K) Tap(fn func(interface{})) Kundalini {
+	k = k.drain()
+	if k.err != nil {
+		return k
+	}
+	logrus.Debug("   tap: ", k.wrapped)
+	if k.serial != nil {
+		k.serial.Lock()
+		defer k.serial.Unlock()
+	}
+	fn(k.wrapped)
+	return k
+}
+
+// dump formats `e`, truncating long sequences and long elements
+func dump(e interface{}) string {
+	if e == nil {
+		return "<nil>"
+	}
+	v := reflect.ValueOf(e)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		elems := make([]string, 0, InspectMaxElems+1)
+		for i := 0; i < v.Len() && i < InspectMaxElems; i++ {
+			elems = append(elems, truncate(fmt.Sprintf("%v", v.Index(i).Interface())))
+		}
+		if v.Len() > InspectMaxElems {
+			elems = append(elems, fmt.Sprintf("… +%d more", v.Len()-InspectMaxElems))
+		}
+		return fmt.Sprintf("%s len=%d [%s]", v.Type(), v.Len(), strings.Join(elems, ", "))
+	}
+	return fmt.Sprintf("%s %s", v.Type(), truncate(fmt.Sprintf("%v", e)))
+}
+
+func truncate(s string) string {
+	r := []rune(s)
+	if len(r) <= InspectMaxWidth {
+		return s
+	}
+	return string(r[:InspectMaxWidth]) + "…"
+}
+
+// typeSummary lists the distinct element types of `e` with their counts
+func typeSummary(e interface{}) string {
+	if e == nil {
+		return "<nil>"
+	}
+	v := reflect.ValueOf(e)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return v.Type().String()
+	}
+
+	order := make([]string, 0)
+	counts := make(map[string]int)
+	for _, t := range slices.Types(v) {
+		name := "<nil>"
+		if t != nil {
+			name = t.String()
+		}
+		if counts[name] == 0 {
+			order = append(order, name)
+		}
+		counts[name]++
+	}
+	summary := make([]string, len(order))
+	for i, name := range order {
+		summary[i] = fmt.Sprintf("%s×%d", name, counts[name])
+	}
+	return "[" + strings.Join(summary, " ") + "]"
+}
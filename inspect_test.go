@@ -0,0 +1,119 @@
+package kundalini_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+)
+
+func TestInspect(t *testing.T) {
+
+	t.Run("should dump the value, types and stack", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		actual, err := Wrap([]int{1, 2}).
+			Push().
+			Concat([]int{3}).
+			Inspect(buf).
+			Release()
+
+		expected := "value: []int len=3 [1, 2, 3]\n" +
+			"types: [int×3]\n" +
+			"stack: 1\n" +
+			"  [0] []int len=2 [1, 2]\n"
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, actual)
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("should summarise the types of an array", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		Wrap([3]interface{}{1, "a", 2}).Inspect(buf)
+
+		lines := strings.Split(buf.String(), "\n")
+		assert.Equal(t, "types: [int×2 string×1]", lines[1])
+	})
+
+	t.Run("should truncate long values", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		v := make([]string, 20)
+		v[0] = strings.Repeat("∆", 100)
+
+		Wrap(v).Inspect(buf)
+
+		lines := strings.Split(buf.String(), "\n")
+		assert.Contains(t, lines[0], strings.Repeat("∆", InspectMaxWidth)+"…,")
+		assert.NotContains(t, lines[0], strings.Repeat("∆", InspectMaxWidth+1))
+		assert.True(t, strings.HasSuffix(lines[0], "… +12 more]"), lines[0])
+	})
+
+	t.Run("should dump the elements of a lazy source", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		actual, err := Wrap(&counter{to: 3}).Inspect(buf).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{0, 1, 2}, actual)
+		assert.Equal(t, "value: []interface {} len=3 [0, 1, 2]\n", strings.SplitAfter(buf.String(), "\n")[0])
+	})
+
+	t.Run("should dump received error", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		actual, err := Wrap(0).Types().Inspect(buf).Release()
+
+		assert.EqualError(t, err, UnsupportedWrappedTypeError.Error())
+		assert.Nil(t, actual)
+		assert.Equal(t, "error: "+UnsupportedWrappedTypeError.Error()+"\nstack: 0\n", buf.String())
+	})
+}
+
+func TestTap(t *testing.T) {
+
+	t.Run("should pass the value through unchanged", func(t *testing.T) {
+		var seen interface{}
+		double := func(x interface{}) interface{} { return x.(int) * 2 }
+
+		actual, err := Wrap([]int{1, 2}).
+			Tap(func(v interface{}) { seen = v }).
+			Map(double).
+			Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, seen)
+		assert.Equal(t, []int{2, 4}, actual)
+	})
+
+	t.Run("should pass the elements of a lazy source without consuming them", func(t *testing.T) {
+		var seen interface{}
+
+		actual, err := Wrap(&counter{to: 3}).
+			Tap(func(v interface{}) {
+				seen = v
+				if it, ok := v.(Iterator); ok {
+					for _, more := it.Next(); more; _, more = it.Next() {
+					}
+				}
+			}).
+			Map(double).
+			Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{0, 1, 2}, seen)
+		assert.Equal(t, []interface{}{0, 2, 4}, actual)
+	})
+
+	t.Run("should not be called on received error", func(t *testing.T) {
+		called := false
+
+		_, err := Wrap(0).Types().Tap(func(interface{}) { called = true }).Release()
+
+		assert.Error(t, err)
+		assert.False(t, called)
+	})
+}
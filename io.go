@@ -0,0 +1,65 @@
+package kundalini
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/iterators"
+)
+
+// FromReader lazily wraps the tokens of `r` as strings
+// `split` defaults to `bufio.ScanLines`, read errors become the chain's error
+func FromReader(r io.Reader, split bufio.SplitFunc) Kundalini {
+	sc := bufio.NewScanner(r)
+	if split != nil {
+		sc.Split(split)
+	}
+	return Wrap(iterators.Scan(sc))
+}
+
+// WriteLines writes each element of `k` formatted by `format` to `w`, one per
+// line, pulling lazy chains one element at a time
+// `format` may return a string, a `[]byte` or any value `fmt.Sprint` handles,
+// a nil `format` writes the elements as they are
+// the lines written before an error are flushed, and a lazy chain is closed
+// even when it is not read to the end
+// it is not named `WriteTo` because that name is reserved for `io.WriterTo`
+func (k *K) WriteLines(w io.Writer, format Fn) (n int64, err error) {
+	it := k.Iterator()
+	defer iterators.Close(it)
+	bw := bufio.NewWriter(w)
+	defer func() {
+		if ferr := bw.Flush(); err == nil {
+			err = ferr
+		}
+	}()
+	for v, ok := it.Next(); ok; v, ok = it.Next() {
+		if format != nil {
+			v = format(v)
+		}
+		var c int
+		switch t := v.(type) {
+		case string:
+			c, err = bw.WriteString(t)
+		case []byte:
+			c, err = bw.Write(t)
+		default:
+			c, err = fmt.Fprint(bw, t)
+		}
+		n += int64(c)
+		if err != nil {
+			return n, err
+		}
+		if err = bw.WriteByte('\n'); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err = iterators.Err(it); err != nil {
+		return n, err
+	}
+	logrus.Debug(" write: ", n)
+	return n, nil
+}
@@ -0,0 +1,141 @@
+package kundalini_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+)
+
+func TestFromReader(t *testing.T) {
+	isError := func(x interface{}) bool { return strings.Contains(x.(string), "ERROR") }
+
+	t.Run("should stream lines into a chain", func(t *testing.T) {
+		log := "INFO start\nERROR disk full\nINFO retry\nERROR disk full again\n"
+
+		actual, err := FromReader(strings.NewReader(log), nil).
+			Filter(isError).
+			Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"ERROR disk full", "ERROR disk full again"}, actual)
+	})
+
+	t.Run("should split with the given SplitFunc", func(t *testing.T) {
+		actual, err := FromReader(strings.NewReader("a bb  ccc\n"), bufio.ScanWords).
+			Map(func(x interface{}) interface{} { return len(x.(string)) }).
+			Reduce(0, func(acc interface{}, x interface{}) interface{} { return acc.(int) + x.(int) }).
+			Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{6}, actual)
+	})
+
+	t.Run("should only read as much as is consumed", func(t *testing.T) {
+		r := &countingReader{r: strings.NewReader(strings.Repeat("line\n", 100000))}
+
+		it := FromReader(r, nil).Iterator()
+		v, ok := it.Next()
+
+		assert.True(t, ok)
+		assert.Equal(t, "line", v)
+		assert.True(t, r.n < 100000, r.n)
+	})
+
+	t.Run("should forward read errors", func(t *testing.T) {
+		failure := fmt.Errorf("disk unplugged")
+		r := io.MultiReader(strings.NewReader("a\nb\n"), iotest.ErrReader(failure))
+
+		actual, err := FromReader(r, nil).Release()
+
+		assert.EqualError(t, err, failure.Error())
+		assert.Nil(t, actual)
+	})
+}
+
+func TestWriteLines(t *testing.T) {
+
+	t.Run("should write formatted elements one per line", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		quote := func(x interface{}) interface{} { return fmt.Sprintf("%q", x) }
+
+		n, err := FromReader(strings.NewReader("a\nb\n"), nil).
+			Map(func(x interface{}) interface{} { return strings.ToUpper(x.(string)) }).
+			WriteLines(buf, quote)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "\"A\"\n\"B\"\n", buf.String())
+		assert.Equal(t, int64(8), n)
+	})
+
+	t.Run("should write elements as they are without a format", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		_, err := Wrap([]int{1, 2}).WriteLines(buf, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "1\n2\n", buf.String())
+	})
+
+	t.Run("should flush the lines written before a source error", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		failure := fmt.Errorf("cursor closed")
+
+		_, err := Wrap(&cursor{rows: []string{"a", "b"}, err: failure}).WriteLines(buf, nil)
+
+		assert.EqualError(t, err, failure.Error())
+		assert.Equal(t, "a\nb\n", buf.String())
+	})
+
+	t.Run("should close the source on a write error", func(t *testing.T) {
+		src := &closing{counter: counter{to: 100}}
+		long := func(x interface{}) interface{} { return strings.Repeat("x", 5000) }
+
+		_, err := Wrap(src).WriteLines(failingWriter{}, long)
+
+		assert.EqualError(t, err, errWrite.Error())
+		assert.True(t, src.closed)
+		assert.Equal(t, 1, src.pulled)
+	})
+
+	t.Run("should forward received error", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		n, err := Wrap(0).Types().WriteLines(buf, nil)
+
+		assert.EqualError(t, err, UnsupportedWrappedTypeError.Error())
+		assert.Equal(t, int64(0), n)
+		assert.Equal(t, "", buf.String())
+	})
+}
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+var errWrite = fmt.Errorf("disk full")
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errWrite }
+
+// closing is a `counter` that records being closed
+type closing struct {
+	counter
+	closed bool
+}
+
+func (c *closing) Close() { c.closed = true }
@@ -0,0 +1,29 @@
+package kundalini
+
+// Iterator is a source of elements that `Wrap` accepts in place of a slice
+// `Next` returns false once there are no more elements
+type Iterator interface {
+	Next() (interface{}, bool)
+}
+
+// ErrIterator is an `Iterator` that can report why it stopped
+// `Err` is checked once `Next` returns false and a non-nil error becomes the
+// error of the chain
+type ErrIterator interface {
+	Iterator
+	Err() error
+}
+
+// Closer is an `Iterator` that can release what it holds before it is
+// exhausted, such as the runs spilled by `Sort`, `Close` may be called more
+// than once
+type Closer interface {
+	Iterator
+	Close()
+}
+
+// Pair is an element yielded by a two value source such as an `iter.Seq2`
+type Pair struct {
+	Key   interface{}
+	Value interface{}
+}
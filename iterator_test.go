@@ -0,0 +1,138 @@
+package kundalini_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+)
+
+// counter yields the ints in [from, to) and records how many were pulled
+type counter struct {
+	from, to int
+	pulled   int
+}
+
+func (c *counter) Next() (interface{}, bool) {
+	if c.from >= c.to {
+		return nil, false
+	}
+	c.from++
+	c.pulled++
+	return c.from - 1, true
+}
+
+// cursor yields its rows and then fails with `err`
+type cursor struct {
+	rows []string
+	err  error
+}
+
+func (c *cursor) Next() (interface{}, bool) {
+	if len(c.rows) == 0 {
+		return nil, false
+	}
+	r := c.rows[0]
+	c.rows = c.rows[1:]
+	return r, true
+}
+
+func (c *cursor) Err() error {
+	return c.err
+}
+
+func TestIterator(t *testing.T) {
+	even := func(x interface{}) bool { return x.(int)%2 == 0 }
+	double := func(x interface{}) interface{} { return x.(int) * 2 }
+	sum := func(acc interface{}, x interface{}) interface{} { return acc.(int) + x.(int) }
+
+	t.Run("should drive Map, Filter and Reduce", func(t *testing.T) {
+		actual, err := Wrap(&counter{from: 0, to: 5}).
+			Filter(even).
+			Map(double).
+			Reduce(3, sum).
+			Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{15}, actual)
+	})
+
+	t.Run("should release lazy chains as []interface{}", func(t *testing.T) {
+		actual, err := Wrap(&counter{from: 0, to: 4}).
+			Map(double).
+			Concat([]int{10}).
+			Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{0, 2, 4, 6, 10}, actual)
+	})
+
+	t.Run("should export lazy chains into typed slices", func(t *testing.T) {
+		buf := []int{}
+
+		_, err := Wrap(&counter{from: 1, to: 4}).
+			Export(reflect.ValueOf(&buf)).
+			Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, buf)
+	})
+
+	t.Run("should not pull elements until consumed", func(t *testing.T) {
+		c := &counter{from: 0, to: 100}
+
+		it := Wrap(c).Filter(even).Map(double).Iterator()
+
+		assert.Equal(t, 0, c.pulled)
+		v, ok := it.Next()
+		assert.True(t, ok)
+		assert.Equal(t, 0, v)
+		v, ok = it.Next()
+		assert.True(t, ok)
+		assert.Equal(t, 4, v)
+		assert.Equal(t, 3, c.pulled)
+	})
+
+	t.Run("should iterate over slices one element at a time", func(t *testing.T) {
+		it := Wrap([]int{1, 2, 3}).Filter(even).Iterator()
+
+		v, ok := it.Next()
+		assert.True(t, ok)
+		assert.Equal(t, 2, v)
+		_, ok = it.Next()
+		assert.False(t, ok)
+	})
+
+	t.Run("should concat an iterator onto a slice", func(t *testing.T) {
+		actual, err := Wrap([]int{7}).
+			Concat(&counter{from: 0, to: 2}).
+			Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{7, 0, 1}, actual)
+	})
+
+	t.Run("should forward the error of the source", func(t *testing.T) {
+		failure := fmt.Errorf("connection reset")
+		upper := func(x interface{}) interface{} { return x.(string) + "!" }
+
+		actual, err := Wrap(&cursor{rows: []string{"a", "b"}, err: failure}).
+			Map(upper).
+			Release()
+
+		assert.EqualError(t, err, failure.Error())
+		assert.Nil(t, actual)
+	})
+
+	t.Run("should report received error through Err", func(t *testing.T) {
+		it := Wrap(0).Types().Iterator()
+
+		_, ok := it.Next()
+		assert.False(t, ok)
+		if assert.Implements(t, (*ErrIterator)(nil), it) {
+			assert.EqualError(t, it.(ErrIterator).Err(), UnsupportedWrappedTypeError.Error())
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package iterators
+
+import (
+	"reflect"
+)
+
+// Poller is an `Iterator` that can report that no element is ready yet
+// instead of blocking until one is
+type Poller interface {
+	Iterator
+	Poll() (v interface{}, ok, ready bool)
+}
+
+type chanIter struct {
+	ch reflect.Value
+}
+
+func (it *chanIter) Next() (interface{}, bool) {
+	v, ok := it.ch.Recv()
+	if !ok {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+func (it *chanIter) Poll() (interface{}, bool, bool) {
+	v, ok := it.ch.TryRecv()
+	if !ok {
+		// a closed channel reports a zero value, an empty one does not
+		return nil, false, v.IsValid()
+	}
+	return v.Interface(), true, true
+}
+
+// FromChan receives the elements of the channel `ch` until it is closed
+func FromChan(ch reflect.Value) Poller {
+	return &chanIter{ch: ch}
+}
+
+// poll pulls from `it` without blocking when it is a `Poller`
+func poll(it Iterator) (interface{}, bool, bool) {
+	if p, ok := it.(Poller); ok {
+		return p.Poll()
+	}
+	v, ok := it.Next()
+	return v, ok, true
+}
@@ -0,0 +1,432 @@
+package iterators
+
+import (
+	"bufio"
+	"reflect"
+	"sync"
+
+	"gitlab.com/jdbellamy/kundalini/slices"
+)
+
+// Iterator yields elements one at a time until `Next` returns false
+type Iterator interface {
+	Next() (interface{}, bool)
+}
+
+// ErrIterator is an `Iterator` that can report why it stopped
+type ErrIterator interface {
+	Iterator
+	Err() error
+}
+
+// Err returns the error reported by `it`, or nil if it cannot report one
+func Err(it Iterator) error {
+	if e, ok := it.(ErrIterator); ok {
+		return e.Err()
+	}
+	return nil
+}
+
+// Typed is an `Iterator` whose elements all share one type
+type Typed interface {
+	Iterator
+	ElemType() reflect.Type
+}
+
+// ElemType returns the type shared by the elements of `it`, or nil if it
+// cannot report one
+func ElemType(it Iterator) reflect.Type {
+	if t, ok := it.(Typed); ok {
+		return t.ElemType()
+	}
+	return nil
+}
+
+// Closer is an `Iterator` holding resources it can release before it is
+// exhausted, `Close` may be called more than once
+type Closer interface {
+	Iterator
+	Close()
+}
+
+// Close releases the resources held by `it` and the iterators it pulls from,
+// `it` yields no more elements once it is closed
+func Close(it Iterator) {
+	if c, ok := it.(Closer); ok {
+		c.Close()
+	}
+}
+
+type failed struct {
+	err error
+}
+
+func (f *failed) Next() (interface{}, bool) { return nil, false }
+func (f *failed) Err() error                { return f.err }
+
+// Failed returns an empty `ErrIterator` that reports `err`
+func Failed(err error) ErrIterator {
+	return &failed{err: err}
+}
+
+type lockedIter struct {
+	mu  sync.Mutex
+	src Iterator
+}
+
+func (it *lockedIter) Next() (interface{}, bool) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return it.src.Next()
+}
+
+func (it *lockedIter) Err() error {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return Err(it.src)
+}
+
+func (it *lockedIter) Poll() (interface{}, bool, bool) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return poll(it.src)
+}
+
+func (it *lockedIter) ElemType() reflect.Type { return ElemType(it.src) }
+
+func (it *lockedIter) Close() {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	Close(it.src)
+}
+
+func (it *lockedIter) String() string { return "<lazy>" }
+
+// Locked makes `it` safe to pull from concurrently, each element is yielded
+// to exactly one caller of `Next`
+func Locked(it Iterator) ErrIterator {
+	if l, ok := it.(*lockedIter); ok {
+		return l
+	}
+	return &lockedIter{src: it}
+}
+
+type sliceIter struct {
+	s reflect.Value
+	i int
+}
+
+func (it *sliceIter) Next() (interface{}, bool) {
+	if it.i >= it.s.Len() {
+		return nil, false
+	}
+	v := it.s.Index(it.i).Interface()
+	it.i++
+	return v, true
+}
+
+func (it *sliceIter) ElemType() reflect.Type { return it.s.Type().Elem() }
+
+// FromSlice iterates over the elements of the slice or array `s`
+func FromSlice(s reflect.Value) Iterator {
+	return &sliceIter{s: s}
+}
+
+type mapIter struct {
+	src Iterator
+	fn  func(interface{}) interface{}
+}
+
+func (it *mapIter) Next() (interface{}, bool) {
+	v, ok := it.src.Next()
+	if !ok {
+		return nil, false
+	}
+	if mapped := it.fn(v); mapped != slices.Keep {
+		return mapped, true
+	}
+	return v, true
+}
+
+func (it *mapIter) Poll() (interface{}, bool, bool) {
+	v, ok, ready := poll(it.src)
+	if !ok {
+		return nil, false, ready
+	}
+	if mapped := it.fn(v); mapped != slices.Keep {
+		return mapped, true, true
+	}
+	return v, true, true
+}
+
+func (it *mapIter) Err() error { return Err(it.src) }
+
+func (it *mapIter) Close() { Close(it.src) }
+
+// Map lazily applys `fn` over each element of `it`
+// elements for which `fn` returns `Keep` are left unchanged
+func Map(it Iterator, fn func(interface{}) interface{}) Iterator {
+	return &mapIter{src: it, fn: fn}
+}
+
+type filterIter struct {
+	src Iterator
+	p   func(interface{}) bool
+}
+
+func (it *filterIter) Next() (interface{}, bool) {
+	for {
+		v, ok := it.src.Next()
+		if !ok {
+			return nil, false
+		}
+		if it.p(v) {
+			return v, true
+		}
+	}
+}
+
+func (it *filterIter) Poll() (interface{}, bool, bool) {
+	for {
+		v, ok, ready := poll(it.src)
+		if !ok {
+			return nil, false, ready
+		}
+		if it.p(v) {
+			return v, true, true
+		}
+	}
+}
+
+func (it *filterIter) Err() error { return Err(it.src) }
+
+func (it *filterIter) ElemType() reflect.Type { return ElemType(it.src) }
+
+func (it *filterIter) Close() { Close(it.src) }
+
+// Filter lazily keeps the elements of `it` that predicate `p` is true for
+func Filter(it Iterator, p func(interface{}) bool) Iterator {
+	return &filterIter{src: it, p: p}
+}
+
+type mapEIter struct {
+	src    Iterator
+	fn     func(interface{}) (interface{}, error)
+	reject func(interface{}, error)
+	err    error
+}
+
+func (it *mapEIter) Next() (interface{}, bool) {
+	for it.err == nil {
+		v, ok := it.src.Next()
+		if !ok {
+			return nil, false
+		}
+		mapped, err := it.fn(v)
+		switch {
+		case err != nil && it.reject == nil:
+			it.err = err
+		case err != nil:
+			it.reject(v, err)
+		case mapped == slices.Keep:
+			return v, true
+		default:
+			return mapped, true
+		}
+	}
+	return nil, false
+}
+
+func (it *mapEIter) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return Err(it.src)
+}
+
+func (it *mapEIter) Close() { Close(it.src) }
+
+// MapE lazily applys `fn` over each element of `it` like `Map`
+// an element `fn` fails on is passed to `reject` and skipped, or stops the
+// iterator with its error when `reject` is nil
+func MapE(it Iterator, fn func(interface{}) (interface{}, error), reject func(interface{}, error)) Iterator {
+	return &mapEIter{src: it, fn: fn, reject: reject}
+}
+
+type filterEIter struct {
+	src    Iterator
+	p      func(interface{}) (bool, error)
+	reject func(interface{}, error)
+	err    error
+}
+
+func (it *filterEIter) Next() (interface{}, bool) {
+	for it.err == nil {
+		v, ok := it.src.Next()
+		if !ok {
+			return nil, false
+		}
+		keep, err := it.p(v)
+		switch {
+		case err != nil && it.reject == nil:
+			it.err = err
+		case err != nil:
+			it.reject(v, err)
+		case keep:
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (it *filterEIter) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return Err(it.src)
+}
+
+func (it *filterEIter) ElemType() reflect.Type { return ElemType(it.src) }
+
+func (it *filterEIter) Close() { Close(it.src) }
+
+// FilterE lazily keeps the elements of `it` that predicate `p` is true for
+// an element `p` fails on is passed to `reject` and skipped, or stops the
+// iterator with its error when `reject` is nil
+func FilterE(it Iterator, p func(interface{}) (bool, error), reject func(interface{}, error)) Iterator {
+	return &filterEIter{src: it, p: p, reject: reject}
+}
+
+type takeIter struct {
+	src Iterator
+	n   int
+}
+
+func (it *takeIter) Next() (interface{}, bool) {
+	if it.n <= 0 {
+		Close(it.src)
+		return nil, false
+	}
+	it.n--
+	v, ok := it.src.Next()
+	if it.n == 0 {
+		Close(it.src)
+	}
+	return v, ok
+}
+
+func (it *takeIter) Err() error { return Err(it.src) }
+
+func (it *takeIter) ElemType() reflect.Type { return ElemType(it.src) }
+
+func (it *takeIter) Close() { Close(it.src) }
+
+// Take lazily yields the first `n` elements of `it` and then stops pulling,
+// closing `it` once it has them
+func Take(it Iterator, n int) Iterator {
+	return &takeIter{src: it, n: n}
+}
+
+type concatIter struct {
+	its []Iterator
+	err error
+}
+
+func (it *concatIter) Next() (interface{}, bool) {
+	for it.err == nil && len(it.its) > 0 {
+		if v, ok := it.its[0].Next(); ok {
+			return v, true
+		}
+		it.err = Err(it.its[0])
+		it.its = it.its[1:]
+	}
+	return nil, false
+}
+
+func (it *concatIter) Err() error { return it.err }
+
+func (it *concatIter) Close() {
+	for _, src := range it.its {
+		Close(src)
+	}
+	it.its = nil
+}
+
+// Concat lazily yields the elements of each of `its` in turn
+func Concat(its ...Iterator) Iterator {
+	return &concatIter{its: its}
+}
+
+// Reduce applys `fn` over the elements of `it` and accumulates the results
+// like `slices.Reduce`, an empty `it` reduces to an empty slice
+func Reduce(it Iterator, acc interface{}, fn func(interface{}, interface{}) interface{}) (interface{}, error) {
+	n := 0
+	for v, ok := it.Next(); ok; v, ok = it.Next() {
+		acc = fn(acc, v)
+		n++
+	}
+	if err := Err(it); err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		return []interface{}{}, nil
+	}
+	if acc == nil {
+		return []interface{}{nil}, nil
+	}
+	accV := reflect.ValueOf(acc)
+	if accV.Kind() == reflect.Slice {
+		return acc, nil
+	}
+	r := reflect.MakeSlice(reflect.SliceOf(accV.Type()), 1, 1)
+	r.Index(0).Set(accV)
+	return r.Interface(), nil
+}
+
+// Drain collects the remaining elements of `it`
+func Drain(it Iterator) ([]interface{}, error) {
+	r := make([]interface{}, 0)
+	for v, ok := it.Next(); ok; v, ok = it.Next() {
+		r = append(r, v)
+	}
+	if err := Err(it); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Collect drains `it` like `Drain`, into a slice of its element type when it
+// is `Typed`
+func Collect(it Iterator) (interface{}, error) {
+	r, err := Drain(it)
+	t := ElemType(it)
+	if err != nil || t == nil {
+		return r, err
+	}
+	s := reflect.MakeSlice(reflect.SliceOf(t), len(r), len(r))
+	for i, v := range r {
+		if v != nil {
+			s.Index(i).Set(reflect.ValueOf(v))
+		}
+	}
+	return s.Interface(), nil
+}
+
+type scanIter struct {
+	sc *bufio.Scanner
+}
+
+func (it *scanIter) Next() (interface{}, bool) {
+	if !it.sc.Scan() {
+		return nil, false
+	}
+	return it.sc.Text(), true
+}
+
+func (it *scanIter) Err() error { return it.sc.Err() }
+
+// Scan yields the tokens of `sc` as strings
+func Scan(sc *bufio.Scanner) ErrIterator {
+	return &scanIter{sc: sc}
+}
@@ -0,0 +1,148 @@
+package iterators
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var InvalidRateError = fmt.Errorf("rate must be positive and burst at least 1")
+var InvalidBatchSizeError = fmt.Errorf("batch size must be at least 1")
+
+// Clock tells the time and waits, it can be replaced to test timing
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time        { return time.Now() }
+func (systemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// SystemClock is the `Clock` of the running system
+var SystemClock Clock = systemClock{}
+
+type throttleIter struct {
+	src    Iterator
+	clock  Clock
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func (it *throttleIter) Next() (interface{}, bool) {
+	v, ok := it.src.Next()
+	if !ok {
+		return nil, false
+	}
+	it.refill()
+	if it.tokens < 1 {
+		it.clock.Sleep(time.Duration((1 - it.tokens) / it.rate * float64(time.Second)))
+		it.refill()
+	}
+	it.tokens--
+	return v, true
+}
+
+func (it *throttleIter) Err() error { return Err(it.src) }
+
+func (it *throttleIter) ElemType() reflect.Type { return ElemType(it.src) }
+
+func (it *throttleIter) Close() { Close(it.src) }
+
+// refill adds the tokens earned since the last refill, up to the burst
+func (it *throttleIter) refill() {
+	now := it.clock.Now()
+	it.tokens += now.Sub(it.last).Seconds() * it.rate
+	if it.tokens > it.burst {
+		it.tokens = it.burst
+	}
+	it.last = now
+}
+
+// Throttle paces the elements of `it` with a token bucket that holds up to
+// `burst` tokens and earns `rate` tokens a second
+// each element takes a token before it is yielded, waiting on `clock` when
+// none are left
+func Throttle(it Iterator, rate float64, burst int, clock Clock) Iterator {
+	if rate <= 0 || burst < 1 {
+		return Failed(InvalidRateError)
+	}
+	return &throttleIter{
+		src:    it,
+		clock:  clock,
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   clock.Now(),
+	}
+}
+
+type batchIter struct {
+	src    Iterator
+	size   int
+	fn     func([]interface{}) ([]interface{}, error)
+	reject func(interface{}, error)
+	out    []interface{}
+	done   bool
+	err    error
+}
+
+func (it *batchIter) Next() (interface{}, bool) {
+	for len(it.out) == 0 {
+		if it.done || it.err != nil {
+			return nil, false
+		}
+		batch := make([]interface{}, 0, it.size)
+		for len(batch) < it.size {
+			v, ok := it.src.Next()
+			if !ok {
+				it.done = true
+				break
+			}
+			batch = append(batch, v)
+		}
+		if len(batch) == 0 || Err(it.src) != nil {
+			return nil, false
+		}
+
+		out, err := it.fn(batch)
+		switch {
+		case err != nil && it.reject == nil:
+			it.err = err
+		case err != nil:
+			it.reject(batch, err)
+		default:
+			it.out = out
+		}
+	}
+	v := it.out[0]
+	it.out = it.out[1:]
+	return v, true
+}
+
+func (it *batchIter) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return Err(it.src)
+}
+
+func (it *batchIter) Close() {
+	Close(it.src)
+	it.out = nil
+	it.done = true
+}
+
+// Batch lazily calls `fn` with chunks of up to `size` elements of `it` and
+// yields the elements it returns
+// a chunk `fn` fails on is passed to `reject` and skipped, or stops the
+// iterator with its error when `reject` is nil
+func Batch(it Iterator, size int, fn func([]interface{}) ([]interface{}, error), reject func(interface{}, error)) Iterator {
+	if size < 1 {
+		return Failed(InvalidBatchSizeError)
+	}
+	return &batchIter{src: it, size: size, fn: fn, reject: reject}
+}
@@ -0,0 +1,255 @@
+package iterators
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+var InvalidWindowError = fmt.Errorf("window sizes, slides and gaps must be positive")
+var LateElementError = fmt.Errorf("element arrived after its windows were emitted")
+
+type windowKind int
+
+const (
+	tumbling windowKind = iota
+	sliding
+	session
+)
+
+// WindowSpec describes how elements are grouped into windows by event time
+type WindowSpec struct {
+	kind  windowKind
+	size  time.Duration
+	slide time.Duration
+}
+
+// Tumbling windows are back to back and `size` long, each element belongs
+// to exactly one
+func Tumbling(size time.Duration) WindowSpec {
+	return WindowSpec{kind: tumbling, size: size, slide: size}
+}
+
+// Sliding windows are `size` long and start every `slide`, an element
+// belongs to each window that covers it, `slide` may not exceed `size` so
+// that every element has one
+func Sliding(size, slide time.Duration) WindowSpec {
+	return WindowSpec{kind: sliding, size: size, slide: slide}
+}
+
+// Session windows group elements that are less than `gap` apart, a session
+// ends `gap` after its last element
+func Session(gap time.Duration) WindowSpec {
+	return WindowSpec{kind: session, size: gap}
+}
+
+// Pane is a window and the elements that fell into it
+type Pane struct {
+	Start    time.Time
+	End      time.Time
+	Elements []interface{}
+}
+
+// Watermark configures when the windows of `Window` are emitted
+// the watermark trails the latest event time seen by `Lateness`, a window is
+// emitted once the watermark passes its end
+// when `Idle` is set and no element has arrived for that long by `Clock`,
+// the watermark advances with the clock so quiet sources still emit
+type Watermark struct {
+	Lateness time.Duration
+	Idle     time.Duration
+	Clock    Clock
+}
+
+type windowIter struct {
+	src       Iterator
+	ts        func(interface{}) time.Time
+	spec      WindowSpec
+	mark      Watermark
+	reject    func(interface{}, error)
+	open      []*Pane
+	ready     []*Pane
+	latest    time.Time
+	watermark time.Time
+	arrived   time.Time
+	seen      bool
+	done      bool
+	err       error
+}
+
+func (it *windowIter) Next() (interface{}, bool) {
+	for len(it.ready) == 0 {
+		if it.done || it.err != nil {
+			return nil, false
+		}
+		v, ok, ready := it.pull()
+		switch {
+		case !ready:
+			it.wait()
+		case !ok:
+			it.done = true
+			if Err(it.src) == nil {
+				it.emit(it.open)
+			}
+		default:
+			it.add(v)
+		}
+	}
+	p := it.ready[0]
+	it.ready = it.ready[1:]
+	return *p, true
+}
+
+func (it *windowIter) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return Err(it.src)
+}
+
+// pull only polls the source when idle sources should advance the watermark
+func (it *windowIter) pull() (interface{}, bool, bool) {
+	if it.mark.Idle > 0 {
+		return poll(it.src)
+	}
+	v, ok := it.src.Next()
+	return v, ok, true
+}
+
+// wait advances the watermark with the clock once the source has been idle
+// for long enough, and otherwise sleeps on it
+func (it *windowIter) wait() {
+	idle := it.mark.Clock.Now().Sub(it.arrived)
+	if it.seen && idle >= it.mark.Idle {
+		it.advance(it.latest.Add(idle))
+		if len(it.ready) > 0 {
+			return
+		}
+	}
+	it.mark.Clock.Sleep(it.mark.Idle / 4)
+}
+
+func (it *windowIter) add(v interface{}) {
+	t := it.ts(v)
+	if it.mark.Idle > 0 {
+		it.arrived = it.mark.Clock.Now()
+	}
+	if !it.seen || t.After(it.latest) {
+		it.latest = t
+	}
+	it.seen = true
+
+	if !it.assign(v, t) {
+		if it.reject == nil {
+			it.err = LateElementError
+			return
+		}
+		it.reject(v, LateElementError)
+	}
+	it.advance(it.latest)
+}
+
+// assign adds `v` to the open windows covering `t`, it reports false when
+// they have all been emitted already
+func (it *windowIter) assign(v interface{}, t time.Time) bool {
+	switch it.spec.kind {
+	case session:
+		end := t.Add(it.spec.size)
+		if !end.After(it.watermark) {
+			return false
+		}
+		merged := &Pane{Start: t, End: end}
+		open := it.open[:0]
+		for _, p := range it.open {
+			if p.Start.Before(merged.End) && merged.Start.Before(p.End) {
+				if p.Start.Before(merged.Start) {
+					merged.Start = p.Start
+				}
+				if p.End.After(merged.End) {
+					merged.End = p.End
+				}
+				merged.Elements = append(merged.Elements, p.Elements...)
+				continue
+			}
+			open = append(open, p)
+		}
+		merged.Elements = append(merged.Elements, v)
+		it.open = append(open, merged)
+		return true
+	}
+
+	added := false
+	for start := t.Truncate(it.spec.slide); start.After(t.Add(-it.spec.size)); start = start.Add(-it.spec.slide) {
+		end := start.Add(it.spec.size)
+		if !end.After(it.watermark) {
+			continue
+		}
+		it.pane(start, end).Elements = append(it.pane(start, end).Elements, v)
+		added = true
+	}
+	return added
+}
+
+// pane returns the open window from `start` to `end`, opening it if needed
+func (it *windowIter) pane(start, end time.Time) *Pane {
+	for _, p := range it.open {
+		if p.Start.Equal(start) {
+			return p
+		}
+	}
+	p := &Pane{Start: start, End: end}
+	it.open = append(it.open, p)
+	return p
+}
+
+// advance moves the watermark to trail `t` and emits the windows it passed
+func (it *windowIter) advance(t time.Time) {
+	if mark := t.Add(-it.mark.Lateness); mark.After(it.watermark) {
+		it.watermark = mark
+	}
+	var due, open []*Pane
+	for _, p := range it.open {
+		if p.End.After(it.watermark) {
+			open = append(open, p)
+		} else {
+			due = append(due, p)
+		}
+	}
+	it.open = open
+	it.emit(due)
+}
+
+// emit queues `panes` in order of their ends and then of their starts
+func (it *windowIter) emit(panes []*Pane) {
+	sort.SliceStable(panes, func(i, j int) bool {
+		if !panes[i].End.Equal(panes[j].End) {
+			return panes[i].End.Before(panes[j].End)
+		}
+		return panes[i].Start.Before(panes[j].Start)
+	})
+	it.ready = append(it.ready, panes...)
+	if it.done {
+		it.open = nil
+	}
+}
+
+// Window lazily groups the elements of `it` into the windows of `spec` by
+// the event time `ts` returns and yields each window as a `Pane` once the
+// watermark passes its end, the windows still open when `it` is exhausted
+// are yielded last
+// an element whose windows were all yielded already is passed to `reject`
+// and skipped, or stops the iterator with `LateElementError` when `reject`
+// is nil
+func Window(it Iterator, ts func(interface{}) time.Time, spec WindowSpec, mark Watermark, reject func(interface{}, error)) Iterator {
+	if spec.size <= 0 || spec.kind != session && (spec.slide <= 0 || spec.slide > spec.size) || mark.Lateness < 0 || mark.Idle < 0 {
+		return Failed(InvalidWindowError)
+	}
+	if mark.Clock == nil {
+		mark.Clock = SystemClock
+	}
+	w := &windowIter{src: it, ts: ts, spec: spec, mark: mark, reject: reject}
+	if mark.Idle > 0 {
+		w.arrived = mark.Clock.Now()
+	}
+	return w
+}
@@ -0,0 +1,98 @@
+package kundalini
+
+import (
+	"reflect"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/slices"
+)
+
+// JoinOption configures how a join matches elements
+type JoinOption func(*joinConfig)
+
+type joinConfig struct {
+	less func(a, b interface{}) bool
+}
+
+// SortMerge joins inputs that are already in ascending order of their keys
+// by `less` in a single pass, without building a hash table
+// unsorted inputs fail with `slices.UnsortedInputError`
+func SortMerge(less func(a, b interface{}) bool) JoinOption {
+	return func(c *joinConfig) {
+		c.less = less
+	}
+}
+
+// Join combines each element of `k` with each element of `other` whose
+// `rightKey` equals its `leftKey`, `other` can be a `Kundalini` or anything
+// `Wrap` accepts, and the results are wrapped as a new slice
+// keys are matched with a hash table unless `SortMerge` is given
+func (k *K) Join(other interface{}, leftKey, rightKey Fn, combine Transform, opts ...JoinOption) Kundalini {
+	return k.join("Join", slices.InnerJoin, other, leftKey, rightKey, combine, opts)
+}
+
+// LeftJoin is `Join` keeping the unmatched elements of `k`, which are
+// combined with nil
+func (k *K) LeftJoin(other interface{}, leftKey, rightKey Fn, combine Transform, opts ...JoinOption) Kundalini {
+	return k.join("LeftJoin", slices.LeftJoin, other, leftKey, rightKey, combine, opts)
+}
+
+// RightJoin is `Join` keeping the unmatched elements of `other`, which are
+// combined with nil
+func (k *K) RightJoin(other interface{}, leftKey, rightKey Fn, combine Transform, opts ...JoinOption) Kundalini {
+	return k.join("RightJoin", slices.RightJoin, other, leftKey, rightKey, combine, opts)
+}
+
+// FullJoin is `Join` keeping the unmatched elements of both sides, which are
+// combined with nil
+func (k *K) FullJoin(other interface{}, leftKey, rightKey Fn, combine Transform, opts ...JoinOption) Kundalini {
+	return k.join("FullJoin", slices.FullJoin, other, leftKey, rightKey, combine, opts)
+}
+
+func (k *K) join(op string, kind slices.JoinKind, other interface{}, leftKey, rightKey Fn, combine Transform, opts []JoinOption) Kundalini {
+	k = k.drain()
+	if k.err != nil {
+		return k
+	}
+	c := &joinConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	o, ok := other.(Kundalini)
+	if !ok {
+		o = Wrap(other)
+	}
+	r, err := o.Release()
+	if err != nil {
+		return k.fail(op, err)
+	}
+	if !sequence(k.wrapped) || !sequence(r) {
+		return k.fail(op, UnsupportedWrappedTypeError)
+	}
+
+	call := k.serialTransform(combine)
+	var v interface{}
+	if c.less != nil {
+		v, err = slices.MergeJoin(reflect.ValueOf(k.wrapped), reflect.ValueOf(r), kind, leftKey, rightKey, call, c.less)
+	} else {
+		v, err = slices.HashJoin(reflect.ValueOf(k.wrapped), reflect.ValueOf(r), kind, leftKey, rightKey, call)
+	}
+	logrus.Debug("  join: ", v)
+	if err != nil {
+		return k.fail(op, err)
+	}
+	return k.next(op, combine, v)
+}
+
+// sequence reports whether `e` is a slice or an array
+func sequence(e interface{}) bool {
+	if e == nil {
+		return false
+	}
+	switch reflect.TypeOf(e).Kind() {
+	case reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
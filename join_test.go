@@ -0,0 +1,149 @@
+package kundalini_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+	"gitlab.com/jdbellamy/kundalini/slices"
+)
+
+type Order struct {
+	ID    int
+	Buyer string
+}
+
+type Buyer struct {
+	Name string
+	City string
+}
+
+var (
+	orders = []Order{{1, "ana"}, {2, "bo"}, {3, "ana"}, {4, "dee"}}
+	buyers = []Buyer{{"ana", "Perth"}, {"bo", "Oslo"}, {"cy", "Lima"}}
+)
+
+func orderBuyer(x interface{}) interface{} { return x.(Order).Buyer }
+func buyerName(x interface{}) interface{}  { return x.(Buyer).Name }
+func byString(a, b interface{}) bool       { return a.(string) < b.(string) }
+
+func describe(o, b interface{}) interface{} {
+	id, city := "-", "-"
+	if o != nil {
+		id = fmt.Sprint(o.(Order).ID)
+	}
+	if b != nil {
+		city = b.(Buyer).City
+	}
+	return id + ":" + city
+}
+
+func TestJoin(t *testing.T) {
+
+	type join func(k Kundalini, other interface{}, opts ...JoinOption) Kundalini
+	variants := map[string]join{
+		"inner": func(k Kundalini, o interface{}, opts ...JoinOption) Kundalini {
+			return k.Join(o, orderBuyer, buyerName, describe, opts...)
+		},
+		"left": func(k Kundalini, o interface{}, opts ...JoinOption) Kundalini {
+			return k.LeftJoin(o, orderBuyer, buyerName, describe, opts...)
+		},
+		"right": func(k Kundalini, o interface{}, opts ...JoinOption) Kundalini {
+			return k.RightJoin(o, orderBuyer, buyerName, describe, opts...)
+		},
+		"full": func(k Kundalini, o interface{}, opts ...JoinOption) Kundalini {
+			return k.FullJoin(o, orderBuyer, buyerName, describe, opts...)
+		},
+	}
+
+	t.Run("should hash join each variant", func(t *testing.T) {
+		type Test struct {
+			variant  string
+			expected interface{}
+		}
+		tests := []Test{
+			{"inner", []string{"1:Perth", "2:Oslo", "3:Perth"}},
+			{"left", []string{"1:Perth", "2:Oslo", "3:Perth", "4:-"}},
+			{"right", []string{"1:Perth", "3:Perth", "2:Oslo", "-:Lima"}},
+			{"full", []string{"1:Perth", "2:Oslo", "3:Perth", "4:-", "-:Lima"}},
+		}
+		for _, test := range tests {
+			actual, err := variants[test.variant](Wrap(orders), buyers).Release()
+			assert.NoError(t, err, test.variant)
+			assert.Equal(t, test.expected, actual, test.variant)
+		}
+	})
+
+	t.Run("should sort-merge join sorted inputs by key order", func(t *testing.T) {
+		sorted := []Order{{1, "ana"}, {3, "ana"}, {2, "bo"}, {4, "dee"}}
+		type Test struct {
+			variant  string
+			expected interface{}
+		}
+		tests := []Test{
+			{"inner", []string{"1:Perth", "3:Perth", "2:Oslo"}},
+			{"left", []string{"1:Perth", "3:Perth", "2:Oslo", "4:-"}},
+			{"right", []string{"1:Perth", "3:Perth", "2:Oslo", "-:Lima"}},
+			{"full", []string{"1:Perth", "3:Perth", "2:Oslo", "-:Lima", "4:-"}},
+		}
+		for _, test := range tests {
+			actual, err := variants[test.variant](Wrap(sorted), buyers, SortMerge(byString)).Release()
+			assert.NoError(t, err, test.variant)
+			assert.Equal(t, test.expected, actual, test.variant)
+		}
+	})
+
+	t.Run("should pair every match of duplicate keys", func(t *testing.T) {
+		pair := func(a, b interface{}) interface{} { return [2]int{a.(int), b.(int)} }
+		mod := func(x interface{}) interface{} { return x.(int) % 2 }
+		less := func(a, b interface{}) bool { return a.(int) < b.(int) }
+		expected := []([2]int){{2, 4}, {2, 6}, {1, 3}, {1, 5}}
+
+		actual, err := Wrap([]int{2, 1}).Join([]int{4, 6, 3, 5}, mod, mod, pair).Release()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+
+		actual, err = Wrap([]int{2, 1}).Join([]int{4, 6, 3, 5}, mod, mod, pair, SortMerge(less)).Release()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should join lazy and wrapped inputs", func(t *testing.T) {
+		id := func(x interface{}) interface{} { return x }
+		sum := func(a, b interface{}) interface{} { return a.(int) + b.(int) }
+		actual, err := Wrap(&counter{to: 5}).Join(Wrap([]int{2, 4, 6}), id, id, sum).Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []int{4, 8}, actual)
+	})
+
+	t.Run("should describe the combine function of a serialized chain", func(t *testing.T) {
+		k := Wrap(orders).Serialize().Join(buyers, orderBuyer, buyerName, describe)
+		assert.Contains(t, k.Describe(DescribeText), "0: Join kundalini_test.describe ")
+	})
+
+	t.Run("should return an empty slice when nothing matches", func(t *testing.T) {
+		actual, err := Wrap(orders).Join([]Buyer{}, orderBuyer, buyerName, describe).Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{}, actual)
+	})
+
+	t.Run("should reject unsorted sort-merge inputs", func(t *testing.T) {
+		actual, err := Wrap(orders).Join(buyers, orderBuyer, buyerName, describe, SortMerge(byString)).Release()
+		assert.EqualError(t, err, slices.UnsortedInputError.Error())
+		assert.Nil(t, actual)
+	})
+
+	t.Run("should reject uncomparable keys", func(t *testing.T) {
+		key := func(x interface{}) interface{} { return []int{x.(int)} }
+		actual, err := Wrap([]int{1}).Join([]int{1}, key, key, describe).Release()
+		assert.EqualError(t, err, slices.UncomparableKeyError.Error())
+		assert.Nil(t, actual)
+	})
+
+	t.Run("should reject unsupported inputs", func(t *testing.T) {
+		actual, err := Wrap(orders).Join(42, orderBuyer, buyerName, describe).Release()
+		assert.Error(t, err)
+		assert.Nil(t, actual)
+	})
+}
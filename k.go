@@ -2,9 +2,12 @@ package kundalini
 
 import (
 	"fmt"
+	"io"
 	"reflect"
+	"sync"
 
 	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/iterators"
 	"gitlab.com/jdbellamy/kundalini/slices"
 )
 
@@ -13,41 +16,116 @@ type Kundalini interface {
 	Concat(slice interface{}) Kundalini
 	Map(fn Fn) Kundalini
 	Filter(p func(interface{}) bool) Kundalini
+	MapE(fn FnE, opts ...StageOption) Kundalini
+	FilterE(p PredicateE, opts ...StageOption) Kundalini
+	Take(n int) Kundalini
+	TopK(n int, less Less) Kundalini
+	Sample(n int, seed int64) Kundalini
+	Shuffle(seed int64) Kundalini
+	Stats() Kundalini
+	Percentiles(ps ...float64) Kundalini
+	Histogram(buckets int) Kundalini
+	Sort(less Less, opts ...SpillOption) Kundalini
+	GroupBy(key Fn, less Less, opts ...SpillOption) Kundalini
+	Window(ts Timestamp, spec WindowSpec, opts ...StageOption) Kundalini
+	Join(other interface{}, leftKey, rightKey Fn, combine Transform, opts ...JoinOption) Kundalini
+	LeftJoin(other interface{}, leftKey, rightKey Fn, combine Transform, opts ...JoinOption) Kundalini
+	RightJoin(other interface{}, leftKey, rightKey Fn, combine Transform, opts ...JoinOption) Kundalini
+	FullJoin(other interface{}, leftKey, rightKey Fn, combine Transform, opts ...JoinOption) Kundalini
+	MapBatched(size int, fn BatchFn, opts ...StageOption) Kundalini
+	Throttle(rate float64, burst int, opts ...StageOption) Kundalini
 	Reduce(acc interface{}, fn Transform) Kundalini
 	Release() (interface{}, error)
 	ReleaseOrPanic() interface{}
+	ReleaseString() (string, error)
 	Types() Kundalini
 	Export(reflect.Value) Kundalini
 	Push() Kundalini
 	Pop() Kundalini
+	Pluck(path string) Kundalini
+	Select(paths ...string) Kundalini
+	SelectMap(paths ...string) Kundalini
+	WhereField(path string, value interface{}) Kundalini
+	SortByField(path string) Kundalini
+	Validate(rules Rules) Kundalini
+	DeadLetter() Kundalini
+	Rejected() []Rejection
+	ExportRejected(ptr reflect.Value) Kundalini
+	Serialize() Kundalini
+	Inspect(w io.Writer) Kundalini
+	Tap(fn func(interface{})) Kundalini
+	Iterator() Iterator
+	Describe(format DescribeFormat) string
+	WriteLines(w io.Writer, format Fn) (int64, error)
+	ToCSV(w io.Writer) error
+	ToJSONLines(w io.Writer) error
+	MarshalJSON() ([]byte, error)
 }
 
 // K holds the elements that kundalini operates on
+//
+// a `K` is never modified once built, every method is safe to call
+// concurrently on the same receiver and chains branched from it stay
+// independent of one another, with these caveats
+//
+//   - released slices are shared by every caller, copy them before writing
+//   - a lazy `K` makes one pass over its source, the chains built from it
+//     share that pass and so each element reaches only one of them
+//   - once an operation that collects a lazy `K`, such as `Release` or
+//     `Push`, has done so, every chain built from it that has not started
+//     pulling reads the collected elements, so collect it first to branch it
+//   - the functions given to stages may be called from several goroutines
+//     at once, use `Serialize` for those that are not safe for that
 type K struct {
 	wrapped interface{}
 	err     error
-	stack   []interface{}
+	stack   *frame
+	stage   int
+	letters *deadLetters
+	lineage *step
+	pulled  *pull
+	serial  *sync.Mutex
 }
 
 type Fn func(interface{}) interface{}
 type Predicate func(interface{}) bool
 type Transform func(interface{}, interface{}) interface{}
 
+// Keep can be returned by a `Fn` to leave the element unchanged
+// a `Fn` that returns nil sets the element to nil where the element type allows it
+var Keep = slices.Keep
+
 var UnsupportedWrappedTypeError = fmt.Errorf("Unsupported encoiled type")
 var OperandTypeMismatchError = fmt.Errorf("type mismatch between wrapped value and operand")
 
 // Wrap wraps an element in an instance of `k`
+// an `Iterator` is evaluated lazily, `Map`, `Filter` and `Concat` pull its
+// elements on demand and other operations collect them into an `[]interface{}`
+// from go1.23 functions shaped like `iter.Seq` and `iter.Seq2` are wrapped as
+// an `Iterator`
+// a channel that can be received from is wrapped as an `Iterator` that ends
+// when the channel is closed
 func Wrap(e interface{}) Kundalini {
 	logrus.Debug("  wrap: ", e)
+	if _, ok := e.(Iterator); !ok {
+		if it, ok := fromSeq(e); ok {
+			e = it
+		} else if it, ok := fromChan(e); ok {
+			e = it
+		}
+	}
+	v, pulled := lazy(e)
 	return &K{
-		wrapped: e,
-		stack:   make([]interface{}, 0),
+		wrapped: v,
+		lineage: newStep(-1, "Wrap", nil, e, 0, nil),
+		pulled:  pulled,
 	}
 }
 
 // Release returns the elements wrapped by `k`
 // `val` is always nil when `err` is populated and vice-versa
 func (k *K) Release() (val interface{}, err error) {
+	k = k.drain()
 	if k.err != nil {
 		return nil, k.err
 	}
@@ -56,6 +134,7 @@ func (k *K) Release() (val interface{}, err error) {
 
 // ReleaseOrPanic either returns the elements wrapped by `k` or panics
 func (k *K) ReleaseOrPanic() interface{} {
+	k = k.drain()
 	if k.err != nil {
 		panic(k.err)
 	}
@@ -64,37 +143,33 @@ func (k *K) ReleaseOrPanic() interface{} {
 
 // Types returns a mapping of the types of each element encoiled by `k`
 func (k *K) Types() Kundalini {
+	k = k.drain()
 	if k.err != nil {
 		return k
 	}
 	switch reflect.TypeOf(k.wrapped).Kind() {
-	case reflect.Slice:
+	case reflect.Slice, reflect.Array:
 		v := slices.Types(reflect.ValueOf(k.wrapped))
 		logrus.Debug(" types: ", v)
-		return &K{
-			wrapped: v,
-			stack:   k.stack,
-		}
+		return k.next("Types", nil, v)
 	}
-	return &K{err: UnsupportedWrappedTypeError}
+	return k.fail("Types", UnsupportedWrappedTypeError)
 }
 
 // Export attempts to copy the current elements of `k` to the provided target
 // a new slice with len and cap based on `k`s elements is generated at `ptr`
 func (k *K) Export(ptr reflect.Value) Kundalini {
+	k = k.drain()
 	if k.err != nil {
 		return k
 	}
 	switch reflect.TypeOf(k.wrapped).Kind() {
-	case reflect.Slice:
+	case reflect.Slice, reflect.Array:
 		v := slices.Export(reflect.ValueOf(k.wrapped), ptr)
 		logrus.Debug("export: ", v)
-		return &K{
-			wrapped: v,
-			stack:   k.stack,
-		}
+		return k.next("Export", nil, v)
 	}
-	return &K{err: UnsupportedWrappedTypeError}
+	return k.fail("Export", UnsupportedWrappedTypeError)
 }
 
 // Map applys `fn` over each element encoiled by `k`
@@ -102,16 +177,21 @@ func (k *K) Map(fn Fn) Kundalini {
 	if k.err != nil {
 		return k
 	}
+	if k.letters != nil {
+		return k.mapE("Map", fn, recoverFn(k.serialFn(fn)))
+	}
+	call := k.serialFn(fn)
+	if it, ok := k.source(); ok {
+		logrus.Debug("   map: ", it)
+		return k.next("Map", fn, iterators.Map(it, call))
+	}
 	switch reflect.TypeOf(k.wrapped).Kind() {
-	case reflect.Slice:
-		v := slices.Map(reflect.ValueOf(k.wrapped), fn)
+	case reflect.Slice, reflect.Array:
+		v := slices.Map(reflect.ValueOf(k.wrapped), call)
 		logrus.Debug("   map: ", v)
-		return &K{
-			wrapped: v,
-			stack:   k.stack,
-		}
+		return k.next("Map", fn, v)
 	}
-	return &K{err: UnsupportedWrappedTypeError}
+	return k.fail("Map", UnsupportedWrappedTypeError)
 }
 
 // Filter keeps the elements of `k` that predicate `p` is true for
@@ -119,16 +199,43 @@ func (k *K) Filter(p func(interface{}) bool) Kundalini {
 	if k.err != nil {
 		return k
 	}
+	if k.letters != nil {
+		return k.filterE("Filter", p, recoverPredicate(k.serialPredicate(p)))
+	}
+	call := k.serialPredicate(p)
+	if it, ok := k.source(); ok {
+		logrus.Debug("filter: ", it)
+		return k.next("Filter", p, iterators.Filter(it, call))
+	}
 	switch reflect.TypeOf(k.wrapped).Kind() {
-	case reflect.Slice:
-		v := slices.Filter(reflect.ValueOf(k.wrapped), p)
+	case reflect.Slice, reflect.Array:
+		v := slices.Filter(reflect.ValueOf(k.wrapped), call)
 		logrus.Debug("filter: ", v)
-		return &K{
-			wrapped: v,
-			stack:   k.stack,
-		}
+		return k.next("Filter", p, v)
 	}
-	return &K{err: UnsupportedWrappedTypeError}
+	return k.fail("Filter", UnsupportedWrappedTypeError)
+}
+
+// Take keeps the first `n` elements of `k`
+// a lazy `k` stops pulling elements once it has `n` of them
+func (k *K) Take(n int) Kundalini {
+	if k.err != nil {
+		return k
+	}
+	if n < 0 {
+		return k.fail("Take", slices.NegativeCountError)
+	}
+	if it, ok := k.source(); ok {
+		logrus.Debug("  take: ", it)
+		return k.next("Take", nil, iterators.Take(it, n))
+	}
+	switch reflect.TypeOf(k.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		v := slices.Take(reflect.ValueOf(k.wrapped), n)
+		logrus.Debug("  take: ", v)
+		return k.next("Take", nil, v)
+	}
+	return k.fail("Take", UnsupportedWrappedTypeError)
 }
 
 // Reduce applys 'fn' over the elements of `k` and accumulates the results
@@ -136,63 +243,242 @@ func (k *K) Reduce(acc interface{}, fn Transform) Kundalini {
 	if k.err != nil {
 		return k
 	}
-	switch reflect.TypeOf(k.wrapped).Kind() {
-	case reflect.Slice:
-		v := slices.Reduce(reflect.ValueOf(k.wrapped), acc, fn)
+	call := k.serialTransform(fn)
+	if it, ok := k.source(); ok {
+		v, err := iterators.Reduce(it, acc, call)
 		logrus.Debug("reduce: ", v)
-		return &K{
-			wrapped: v,
-			stack:   k.stack,
+		if err != nil {
+			return k.fail("Reduce", err)
 		}
+		return k.next("Reduce", fn, v)
+	}
+	switch reflect.TypeOf(k.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		v := slices.Reduce(reflect.ValueOf(k.wrapped), acc, call)
+		logrus.Debug("reduce: ", v)
+		return k.next("Reduce", fn, v)
 	}
-	return &K{err: UnsupportedWrappedTypeError}
+	return k.fail("Reduce", UnsupportedWrappedTypeError)
 }
 
 // Concat appends the elements of `e` to the elements wrapped by `k`
+// the result is lazy when either `k` or `e` is an `Iterator`
 func (k *K) Concat(e interface{}) Kundalini {
 	if k.err != nil {
 		return k
 	}
+	_, lazy := k.wrapped.(Iterator)
+	if _, ok := e.(Iterator); ok || lazy {
+		l, r := k.Iterator(), Wrap(e).Iterator()
+		if err := iterators.Err(r); err != nil {
+			return k.fail("Concat", slices.TypeMismatchError)
+		}
+		logrus.Debug("concat: ", l, r)
+		return k.next("Concat", nil, iterators.Concat(l, r))
+	}
 	switch reflect.TypeOf(k.wrapped).Kind() {
-	case reflect.Slice:
+	case reflect.Slice, reflect.Array:
 		v, err := slices.Concat(reflect.ValueOf(k.wrapped), e)
 		logrus.Debug("concat: ", v)
 		if err != nil {
-			return &K{err: err}
+			return k.fail("Concat", err)
 		} else {
-			return &K{
-				wrapped: v,
-				stack:   k.stack,
-			}
+			return k.next("Concat", nil, v)
 		}
 	}
-	return &K{err: UnsupportedWrappedTypeError}
+	return k.fail("Concat", UnsupportedWrappedTypeError)
 }
 
 // Push appends the elements wrapped by `k` to an internal stack
+// the stack is persistent, chains branched from `k` push and pop without
+// affecting one another
 func (k *K) Push() Kundalini {
+	k = k.drain()
 	if k.err != nil {
 		return k
 	}
 	logrus.Debug("pushed: ", k.wrapped)
-	stack := append(k.stack, k.wrapped)
-	return &K{
-		wrapped: k.wrapped,
-		stack:   stack,
-	}
+	return k.step("Push", nil, k.wrapped, k.stack.push(k.wrapped))
 }
 
 // Pop sets the value wrapped by `k` to the tail of the internal stack
+// popping an empty stack fails with `EmptyStackError`
 func (k *K) Pop() Kundalini {
 	if k.err != nil {
 		return k
 	}
-	idx := len(k.stack) - 1
-	tail := k.stack[idx]
-	left := k.stack[:idx]
-	logrus.Debug("popped: ", tail)
+	if k.stack == nil {
+		return k.fail("Pop", EmptyStackError)
+	}
+	logrus.Debug("popped: ", k.stack.value)
+	return k.step("Pop", nil, k.stack.value, k.stack.below)
+}
+
+// Pluck replaces each struct element of `k` with the value of the field at
+// `path`, a dotted list of exported field names or `json` tag names
+func (k *K) Pluck(path string) Kundalini {
+	k = k.drain()
+	if k.err != nil {
+		return k
+	}
+	switch reflect.TypeOf(k.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		v, err := slices.Pluck(reflect.ValueOf(k.wrapped), path)
+		logrus.Debug(" pluck: ", v)
+		if err != nil {
+			return k.fail("Pluck", err)
+		}
+		return k.next("Pluck", nil, v)
+	}
+	return k.fail("Pluck", UnsupportedWrappedTypeError)
+}
+
+// Select projects each struct element of `k` onto a generated struct with
+// only the fields at `paths`
+func (k *K) Select(paths ...string) Kundalini {
+	k = k.drain()
+	if k.err != nil {
+		return k
+	}
+	switch reflect.TypeOf(k.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		v, err := slices.Select(reflect.ValueOf(k.wrapped), paths)
+		logrus.Debug("select: ", v)
+		if err != nil {
+			return k.fail("Select", err)
+		}
+		return k.next("Select", nil, v)
+	}
+	return k.fail("Select", UnsupportedWrappedTypeError)
+}
+
+// SelectMap projects each struct element of `k` onto a map keyed by `paths`
+func (k *K) SelectMap(paths ...string) Kundalini {
+	k = k.drain()
+	if k.err != nil {
+		return k
+	}
+	switch reflect.TypeOf(k.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		v, err := slices.SelectMap(reflect.ValueOf(k.wrapped), paths)
+		logrus.Debug("select: ", v)
+		if err != nil {
+			return k.fail("SelectMap", err)
+		}
+		return k.next("SelectMap", nil, v)
+	}
+	return k.fail("SelectMap", UnsupportedWrappedTypeError)
+}
+
+// WhereField keeps the struct elements of `k` whose field at `path` equals `value`
+func (k *K) WhereField(path string, value interface{}) Kundalini {
+	k = k.drain()
+	if k.err != nil {
+		return k
+	}
+	switch reflect.TypeOf(k.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		v, err := slices.WhereField(reflect.ValueOf(k.wrapped), path, value)
+		logrus.Debug(" where: ", v)
+		if err != nil {
+			return k.fail("WhereField", err)
+		}
+		return k.next("WhereField", nil, v)
+	}
+	return k.fail("WhereField", UnsupportedWrappedTypeError)
+}
+
+// SortByField orders the struct elements of `k` by the field at `path`
+func (k *K) SortByField(path string) Kundalini {
+	k = k.drain()
+	if k.err != nil {
+		return k
+	}
+	switch reflect.TypeOf(k.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		v, err := slices.SortByField(reflect.ValueOf(k.wrapped), path)
+		logrus.Debug("  sort: ", v)
+		if err != nil {
+			return k.fail("SortByField", err)
+		}
+		return k.next("SortByField", nil, v)
+	}
+	return k.fail("SortByField", UnsupportedWrappedTypeError)
+}
+
+// Iterator returns an `Iterator` over the elements of `k`
+// errors, including those of a lazy source, are reported through its `Err`
+// an iterator that is not read to the end must be closed when it is a
+// `Closer`, or what its source holds, such as the runs spilled by `Sort` or
+// the goroutine of an `iter.Seq`, is not released
+func (k *K) Iterator() Iterator {
+	if k.err != nil {
+		return iterators.Failed(k.err)
+	}
+	if it, ok := k.source(); ok {
+		return it
+	}
+	switch reflect.TypeOf(k.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		return iterators.FromSlice(reflect.ValueOf(k.wrapped))
+	}
+	return iterators.Failed(UnsupportedWrappedTypeError)
+}
+
+// drain collects the elements of a lazily evaluated `k` into a slice
+// the elements are collected once and shared by every caller
+func (k *K) drain() *K {
+	if k.err != nil || k.pulled == nil {
+		return k
+	}
+	k.pulled.collect(k.wrapped.(Iterator))
+	logrus.Debug(" drain: ", k.pulled.v)
+	if k.pulled.err != nil {
+		d := k.fail("Drain", k.pulled.err)
+		d.lineage.stage = -1
+		return d
+	}
+	return &K{
+		wrapped: k.pulled.v,
+		stack:   k.stack,
+		stage:   k.stage,
+		letters: k.letters,
+		lineage: k.lineage,
+		serial:  k.serial,
+	}
+}
+
+// next returns the stage that follows `k` with `v` as its value, recording
+// the operation `op` and its function `fn` in the lineage
+func (k *K) next(op string, fn interface{}, v interface{}) *K {
+	return k.step(op, fn, v, k.stack)
+}
+
+// step is `next` for operations that change the stack
+func (k *K) step(op string, fn interface{}, v interface{}, stack *frame) *K {
+	l := newStep(k.stage, op, fn, v, stack.len(), k.lineage)
+	v, pulled := lazy(v)
+	return &K{
+		wrapped: v,
+		stack:   stack,
+		stage:   k.stage + 1,
+		letters: k.letters,
+		lineage: l,
+		pulled:  pulled,
+		serial:  k.serial,
+	}
+}
+
+// fail returns a chain holding `err` that records the operation `op` failing
+// in the lineage of `k`
+func (k *K) fail(op string, err error) *K {
+	s := newStep(k.stage, op, nil, nil, k.stack.len(), k.lineage)
+	s.err = err
 	return &K{
-		wrapped: tail,
-		stack:   left,
+		err:     err,
+		stage:   k.stage + 1,
+		letters: k.letters,
+		lineage: s,
+		serial:  k.serial,
 	}
 }
@@ -0,0 +1,106 @@
+package kundalini
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DescribeFormat selects how `Describe` renders the lineage of a chain
+type DescribeFormat int
+
+const (
+	// DescribeText renders one numbered line for each step
+	DescribeText DescribeFormat = iota
+	// DescribeDOT renders a Graphviz digraph
+	DescribeDOT
+)
+
+// step is an operation in the lineage of a chain, steps are shared between
+// the chains built from a common parent and never change once recorded
+type step struct {
+	stage  int
+	op     string
+	fn     string
+	count  int
+	depth  int
+	err    error
+	parent *step
+}
+
+// newStep records `op` applying `fn` and producing `v` with a stack `depth`
+// deep as the stage numbered `stage`, or -1 when `op` is not a stage
+// the element count of a lazy `v` is not known and recorded as -1
+func newStep(stage int, op string, fn interface{}, v interface{}, depth int, parent *step) *step {
+	s := &step{stage: stage, op: op, fn: funcName(fn), count: -1, depth: depth, parent: parent}
+	if v != nil {
+		switch rv := reflect.ValueOf(v); rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			s.count = rv.Len()
+		}
+	}
+	return s
+}
+
+func (s *step) String() string {
+	parts := []string{s.op}
+	if s.fn != "" {
+		parts = append(parts, s.fn)
+	}
+	if s.err != nil {
+		return fmt.Sprintf("%s failed: %v", strings.Join(parts, " "), s.err)
+	}
+	count := "lazy"
+	if s.count >= 0 {
+		count = fmt.Sprint(s.count)
+	}
+	return fmt.Sprintf("%s [count=%s depth=%d]", strings.Join(parts, " "), count, s.depth)
+}
+
+func (s *step) label() string {
+	if s.stage < 0 {
+		return "-"
+	}
+	return fmt.Sprint(s.stage)
+}
+
+// steps lists the lineage of `k` from `Wrap` onwards
+func (k *K) steps() []*step {
+	steps := make([]*step, 0)
+	for s := k.lineage; s != nil; s = s.parent {
+		steps = append(steps, s)
+	}
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return steps
+}
+
+// Describe renders the lineage of `k`, each operation that produced it with
+// its function, element count and stack depth, as `format`
+// operations are numbered by stage like `Rejection`s, those that are not
+// stages, such as `Wrap`, are marked with a dash
+func (k *K) Describe(format DescribeFormat) string {
+	b := &strings.Builder{}
+	steps := k.steps()
+	if format == DescribeDOT {
+		b.WriteString("digraph lineage {\n  rankdir=LR;\n  node [shape=box];\n")
+		for i, s := range steps {
+			attrs := ""
+			if s.err != nil {
+				attrs = ", color=red"
+			}
+			label := strings.Replace(s.String(), " [", "\n[", 1)
+			fmt.Fprintf(b, "  s%d [label=%q%s];\n", i, label, attrs)
+			if i > 0 {
+				fmt.Fprintf(b, "  s%d -> s%d;\n", i-1, i)
+			}
+		}
+		b.WriteString("}\n")
+		return b.String()
+	}
+	for _, s := range steps {
+		fmt.Fprintf(b, "%s: %s\n", s.label(), s)
+	}
+	return b.String()
+}
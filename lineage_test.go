@@ -0,0 +1,83 @@
+package kundalini_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+)
+
+func TestDescribe(t *testing.T) {
+
+	t.Run("should render the lineage as text", func(t *testing.T) {
+		k := Wrap([]int{1, 2, 3, 4}).
+			Map(double).
+			Push().
+			Filter(small).
+			Take(1).
+			Pop()
+
+		expected := `-: Wrap [count=4 depth=0]
+0: Map kundalini_test.double [count=4 depth=0]
+1: Push [count=4 depth=1]
+2: Filter kundalini_test.small [count=4 depth=1]
+3: Take [count=1 depth=1]
+4: Pop [count=4 depth=0]
+`
+		assert.Equal(t, expected, k.Describe(DescribeText))
+	})
+
+	t.Run("should record lazy stages and the failure that ended the chain", func(t *testing.T) {
+		fail := func(x interface{}) (interface{}, error) { return nil, errors.New("no") }
+		k := Wrap(&counter{to: 3}).Map(inc).MapE(fail).Push()
+
+		expected := `-: Wrap [count=lazy depth=0]
+0: Map kundalini_test.inc [count=lazy depth=0]
+1: MapE kundalini_test.TestDescribe.func2.1 [count=lazy depth=0]
+-: Drain failed: stage 1: no
+`
+		assert.Equal(t, expected, k.Describe(DescribeText))
+	})
+
+	t.Run("should keep the lineage of branches apart", func(t *testing.T) {
+		base := Wrap([]int{1, 2}).Map(double)
+		left := base.Filter(small)
+		right := base.Take(1)
+
+		assert.Contains(t, left.Describe(DescribeText), "1: Filter")
+		assert.NotContains(t, left.Describe(DescribeText), "Take")
+		assert.Contains(t, right.Describe(DescribeText), "1: Take")
+		assert.Equal(t, "-: Wrap [count=2 depth=0]\n0: Map kundalini_test.double [count=2 depth=0]\n", base.Describe(DescribeText))
+	})
+
+	t.Run("should name the Fn of a dead-letter Map", func(t *testing.T) {
+		k := Wrap([]int{1}).DeadLetter().Map(double)
+
+		assert.Equal(t, "-: Wrap [count=1 depth=0]\n-: DeadLetter [count=1 depth=0]\n0: Map kundalini_test.double [count=1 depth=0]\n", k.Describe(DescribeText))
+	})
+
+	t.Run("should render the lineage as DOT", func(t *testing.T) {
+		k := Wrap([]int{1}).Map(double).Types().Types()
+
+		expected := `digraph lineage {
+  rankdir=LR;
+  node [shape=box];
+  s0 [label="Wrap\n[count=1 depth=0]"];
+  s1 [label="Map kundalini_test.double\n[count=1 depth=0]"];
+  s0 -> s1;
+  s2 [label="Types\n[count=1 depth=0]"];
+  s1 -> s2;
+  s3 [label="Types\n[count=1 depth=0]"];
+  s2 -> s3;
+}
+`
+		assert.Equal(t, expected, k.Describe(DescribeDOT))
+	})
+
+	t.Run("should mark failed steps in DOT", func(t *testing.T) {
+		k := Wrap(0).Types()
+
+		assert.Contains(t, k.Describe(DescribeDOT), `s1 [label="Types failed: Unsupported encoiled type", color=red];`)
+	})
+}
@@ -0,0 +1,156 @@
+package kundalini
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/slices"
+)
+
+// Optimize returns a `Pipeline` that gives the same results as `p` in fewer
+// stages, `p` is left unchanged
+//
+//   - consecutive `map`s are fused into one `Fn`
+//   - adjacent `filter`s are merged into one `Predicate`
+//   - a `push` directly followed by a `pop` is dropped
+//   - a `filter` followed by a `take` stops at the last element it needs
+func (p *Pipeline) Optimize() *Pipeline {
+	out := make([]stage, 0, len(p.stages))
+	for _, s := range p.stages {
+		if len(out) == 0 {
+			out = append(out, s)
+			continue
+		}
+		last := &out[len(out)-1]
+		switch {
+		case s.op == "map" && last.op == "map":
+			if last.fns == nil {
+				last.fns = []Fn{last.fn}
+			}
+			last.fns = append(last.fns[:len(last.fns):len(last.fns)], s.fn)
+			last.name += "|" + s.name
+		case s.op == "filter" && last.op == "filter":
+			last.p = both(last.p, s.p)
+			last.name += "&" + s.name
+		case s.op == "pop" && last.op == "push":
+			out = out[:len(out)-1]
+		case s.op == "take" && last.op == "filter":
+			last.op, last.n = "filter+take", s.n
+		case s.op == "take" && (last.op == "take" || last.op == "filter+take"):
+			if s.n < last.n {
+				last.n = s.n
+			}
+		default:
+			out = append(out, s)
+		}
+	}
+	logrus.Debug("optimz: ", len(p.stages), " -> ", len(out))
+	return &Pipeline{stages: out}
+}
+
+// Explain lists the stages of `p` and those of its optimized form
+func (p *Pipeline) Explain() string {
+	b := &strings.Builder{}
+	b.WriteString("original:\n")
+	p.list(b)
+	b.WriteString("optimized:\n")
+	p.Optimize().list(b)
+	return b.String()
+}
+
+func (p *Pipeline) list(b *strings.Builder) {
+	for i, s := range p.stages {
+		fmt.Fprintf(b, "  %d: %s\n", i, s)
+	}
+}
+
+func (s stage) String() string {
+	parts := []string{s.op}
+	if s.name != "" {
+		parts = append(parts, s.name)
+	}
+	if s.op == "reduce" {
+		parts = append(parts, fmt.Sprintf("init=%v", s.init))
+	}
+	if s.op == "take" || s.op == "filter+take" {
+		parts = append(parts, fmt.Sprint(s.n))
+	}
+	return strings.Join(parts, " ")
+}
+
+// mapFused maps `k` with each of `fns` in one pass, the result of each is
+// stored the way `Map` would store it in `k` before it is passed on
+func mapFused(k Kundalini, fns []Fn) Kundalini {
+	kk, ok := k.(*K)
+	if !ok || kk.err != nil {
+		for _, fn := range fns {
+			k = k.Map(fn)
+		}
+		return k
+	}
+	store := kk.store()
+	return k.Map(func(x interface{}) interface{} {
+		for _, fn := range fns {
+			x = store(x, fn(x))
+		}
+		return x
+	})
+}
+
+// store returns what `Map` keeps for an element `x` of `k` mapped to `m`,
+// slices keep their element type while lazy chains pass nil on
+func (k *K) store() func(x, m interface{}) interface{} {
+	var elemT reflect.Type
+	if _, lazy := k.wrapped.(Iterator); !lazy {
+		switch t := reflect.TypeOf(k.wrapped); t.Kind() {
+		case reflect.Slice, reflect.Array:
+			elemT = t.Elem()
+		}
+	}
+	return func(x, m interface{}) interface{} {
+		switch {
+		case m == Keep:
+			return x
+		case m == nil && elemT != nil && nillable(elemT):
+			return reflect.Zero(elemT).Interface()
+		case m == nil && elemT != nil:
+			return x
+		}
+		return m
+	}
+}
+
+func nillable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return true
+	}
+	return false
+}
+
+// both returns a `Predicate` that is true when `p` and `q` are
+func both(p, q Predicate) Predicate {
+	return func(x interface{}) bool {
+		return p(x) && q(x)
+	}
+}
+
+// filterTake keeps the first `n` elements of `k` that `p` is true for
+func filterTake(k Kundalini, p Predicate, n int) Kundalini {
+	kk, ok := k.(*K)
+	if !ok || kk.err != nil || kk.letters != nil || n < 0 {
+		return k.Filter(p).Take(n)
+	}
+	if _, lazy := kk.wrapped.(Iterator); lazy {
+		return k.Filter(p).Take(n)
+	}
+	switch reflect.TypeOf(kk.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		v := slices.FilterTake(reflect.ValueOf(kk.wrapped), p, n)
+		logrus.Debug("fitake: ", v)
+		return kk.next("FilterTake", p, v)
+	}
+	return kk.fail("FilterTake", UnsupportedWrappedTypeError)
+}
@@ -0,0 +1,154 @@
+package kundalini_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+	"gitlab.com/jdbellamy/kundalini/slices"
+)
+
+func double(x interface{}) interface{} { return x.(int) * 2 }
+func inc(x interface{}) interface{}    { return x.(int) + 1 }
+func isEven(x interface{}) bool        { return x.(int)%2 == 0 }
+func small(x interface{}) bool         { return x.(int) < 100 }
+
+func TestTake(t *testing.T) {
+
+	t.Run("should keep the first n elements", func(t *testing.T) {
+		type Test struct {
+			input    interface{}
+			n        int
+			expected interface{}
+		}
+		tests := []Test{
+			{[]int{1, 2, 3}, 2, []int{1, 2}},
+			{[]int{1, 2, 3}, 5, []int{1, 2, 3}},
+			{[3]string{"a", "b", "c"}, 1, []string{"a"}},
+			{[]int{}, 1, []int{}},
+		}
+		for _, test := range tests {
+			actual, err := Wrap(test.input).Take(test.n).Release()
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		}
+	})
+
+	t.Run("should stop pulling a lazy source", func(t *testing.T) {
+		src := &counter{to: 1000}
+
+		actual, err := Wrap(src).Filter(isEven).Take(3).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{0, 2, 4}, actual)
+		assert.Equal(t, 5, src.pulled)
+	})
+
+	t.Run("should raise error when n is negative", func(t *testing.T) {
+		_, err := Wrap([]int{1}).Take(-1).Release()
+
+		assert.Equal(t, slices.NegativeCountError, err)
+	})
+
+	t.Run("should forward received error", func(t *testing.T) {
+		_, err := Wrap(0).Types().Take(1).Release()
+
+		assert.Equal(t, UnsupportedWrappedTypeError, err)
+	})
+}
+
+func TestOptimize(t *testing.T) {
+
+	t.Run("should give the same results as the original", func(t *testing.T) {
+		keepOdd := func(x interface{}) interface{} {
+			if x.(int)%2 != 0 {
+				return Keep
+			}
+			return nil
+		}
+		pipelines := []*Pipeline{
+			NewPipeline().Map(double).Map(inc).Map(double),
+			NewPipeline().Map(keepOdd).Map(inc),
+			NewPipeline().Filter(isEven).Filter(small).Map(double),
+			NewPipeline().Map(inc).Push().Pop().Map(double),
+			NewPipeline().Filter(isEven).Take(2).Take(3),
+			NewPipeline().Take(4).Filter(isEven),
+			NewPipeline().Push().Map(double).Pop(),
+		}
+		for _, p := range pipelines {
+			input := []int{1, 2, 3, 4, 5, 6, 200}
+			expected, err := p.Apply(Wrap(input)).Release()
+			assert.NoError(t, err)
+			actual, err := p.Optimize().Apply(Wrap(input)).Release()
+			assert.NoError(t, err)
+			assert.Equal(t, expected, actual, p.Explain())
+		}
+	})
+
+	t.Run("should pass nil results on like the original", func(t *testing.T) {
+		toNil := func(x interface{}) interface{} { return nil }
+		keep := func(x interface{}) interface{} { return Keep }
+		p := NewPipeline().Map(toNil).Map(keep)
+		inputs := []func() interface{}{
+			func() interface{} { return []interface{}{1, 2} },
+			func() interface{} { return []int{1, 2} },
+			func() interface{} { return []*int{new(int)} },
+			func() interface{} { return &counter{to: 2} },
+		}
+		for _, input := range inputs {
+			expected, err := p.Apply(Wrap(input())).Release()
+			assert.NoError(t, err)
+			actual, err := p.Optimize().Apply(Wrap(input())).Release()
+			assert.NoError(t, err)
+			assert.Equal(t, expected, actual, p.Explain())
+		}
+	})
+
+	t.Run("should stop calling the predicate after n elements", func(t *testing.T) {
+		calls := 0
+		counted := func(x interface{}) bool {
+			calls++
+			return isEven(x)
+		}
+		p := NewPipeline().Filter(counted).Take(2).Optimize()
+
+		actual, err := p.Apply(Wrap([]int{1, 2, 3, 4, 5, 6})).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2, 4}, actual)
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("should optimize loaded specs", func(t *testing.T) {
+		reg := NewRegistry().RegisterFn("double", double).RegisterPredicate("isEven", isEven)
+		spec := `{"stages": [{"map": "double"}, {"map": "double"}, "push", "pop", {"filter": "isEven"}, {"take": 2}]}`
+		p, err := LoadJSON(strings.NewReader(spec), reg)
+		assert.NoError(t, err)
+
+		actual, err := p.Optimize().Apply(Wrap([]int{1, 2, 3})).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{4, 8}, actual)
+	})
+}
+
+func TestExplain(t *testing.T) {
+	p := NewPipeline().Map(double).Map(inc).Push().Pop().Filter(isEven).Filter(small).Take(3).Reduce(0, nil)
+
+	expected := `original:
+  0: map kundalini_test.double
+  1: map kundalini_test.inc
+  2: push
+  3: pop
+  4: filter kundalini_test.isEven
+  5: filter kundalini_test.small
+  6: take 3
+  7: reduce init=0
+optimized:
+  0: map kundalini_test.double|kundalini_test.inc
+  1: filter+take kundalini_test.isEven&kundalini_test.small 3
+  2: reduce init=0
+`
+	assert.Equal(t, expected, p.Explain())
+}
@@ -0,0 +1,67 @@
+package kundalini
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// NewPipeline returns an empty `Pipeline` to record stages on
+// stages recorded with a function are named after it
+func NewPipeline() *Pipeline {
+	return &Pipeline{stages: make([]stage, 0)}
+}
+
+// Map records a `map` stage
+func (p *Pipeline) Map(fn Fn) *Pipeline {
+	return p.add(stage{op: "map", name: funcName(fn), fn: fn})
+}
+
+// Filter records a `filter` stage
+func (p *Pipeline) Filter(pred Predicate) *Pipeline {
+	return p.add(stage{op: "filter", name: funcName(pred), p: pred})
+}
+
+// Reduce records a `reduce` stage
+func (p *Pipeline) Reduce(init interface{}, fn Transform) *Pipeline {
+	return p.add(stage{op: "reduce", name: funcName(fn), t: fn, init: init})
+}
+
+// Take records a `take` stage
+func (p *Pipeline) Take(n int) *Pipeline {
+	return p.add(stage{op: "take", n: n})
+}
+
+// Push records a `push` stage
+func (p *Pipeline) Push() *Pipeline {
+	return p.add(stage{op: "push"})
+}
+
+// Pop records a `pop` stage
+func (p *Pipeline) Pop() *Pipeline {
+	return p.add(stage{op: "pop"})
+}
+
+// Types records a `types` stage
+func (p *Pipeline) Types() *Pipeline {
+	return p.add(stage{op: "types"})
+}
+
+func (p *Pipeline) add(s stage) *Pipeline {
+	p.stages = append(p.stages, s)
+	return p
+}
+
+// funcName returns the name of the function `fn` without its package path
+func funcName(fn interface{}) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+	name := f.Name()
+	return name[strings.LastIndex(name, "/")+1:]
+}
@@ -0,0 +1,53 @@
+package kundalini
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/records"
+)
+
+// Reject is a record that a source could not decode
+type Reject = records.Reject
+
+// CSVOptions configures how `FromCSV` reads rows
+type CSVOptions struct {
+	// Columns names the columns when the CSV has no header row
+	// when empty the first row is read as the header
+	Columns []string
+	// Comma is the field delimiter, ',' by default
+	Comma rune
+	// OnReject receives the rows that cannot be decoded
+	// when nil the first bad row becomes the chain's error
+	OnReject func(Reject)
+}
+
+// FromCSV lazily wraps the rows of `r` decoded into structs typed like
+// `prototype`, columns are matched to fields by `csv` tag or field name
+func FromCSV(r io.Reader, prototype interface{}, opts CSVOptions) Kundalini {
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	return Wrap(records.CSV(cr, prototype, opts.Columns, opts.OnReject))
+}
+
+// FromJSONLines lazily wraps each line of `r` decoded into a struct typed like
+// `prototype`, lines that cannot be decoded are passed to `onReject` or, when
+// it is nil, become the chain's error
+func FromJSONLines(r io.Reader, prototype interface{}, onReject func(Reject)) Kundalini {
+	return Wrap(records.JSONLines(r, prototype, onReject))
+}
+
+// ToCSV writes the struct elements of `k` to `w` as CSV with a header row
+func (k *K) ToCSV(w io.Writer) error {
+	logrus.Debug("   csv: ", k.wrapped)
+	return records.WriteCSV(csv.NewWriter(w), k.Iterator())
+}
+
+// ToJSONLines writes each struct element of `k` to `w` as a line of JSON
+func (k *K) ToJSONLines(w io.Writer) error {
+	logrus.Debug(" jsonl: ", k.wrapped)
+	return records.WriteJSONLines(w, k.Iterator())
+}
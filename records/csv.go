@@ -0,0 +1,167 @@
+package records
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gitlab.com/jdbellamy/kundalini/iterators"
+)
+
+type csvIter struct {
+	r        *csv.Reader
+	t        reflect.Type
+	ptr      bool
+	header   []string
+	fields   [][]int
+	onReject func(Reject)
+	err      error
+}
+
+// CSV decodes the rows of `r` into structs typed like `prototype`
+// columns are matched to fields by their `csv` tag or name, ignoring case,
+// and are named by `header` or, when it is empty, by the first row
+// rows that cannot be decoded are passed to `onReject`, or stop the iterator
+// with a `*RecordError` when it is nil
+func CSV(r *csv.Reader, prototype interface{}, header []string, onReject func(Reject)) iterators.ErrIterator {
+	t, ptr, err := recordType(prototype)
+	if err != nil {
+		return iterators.Failed(err)
+	}
+	r.FieldsPerRecord = -1
+	return &csvIter{r: r, t: t, ptr: ptr, header: header, onReject: onReject}
+}
+
+func (it *csvIter) Next() (interface{}, bool) {
+	if it.err != nil {
+		return nil, false
+	}
+	if it.fields == nil && !it.readHeader() {
+		return nil, false
+	}
+
+	for {
+		rec, err := it.r.Read()
+		if err == io.EOF {
+			return nil, false
+		}
+		line := 0
+		if pe, ok := err.(*csv.ParseError); ok {
+			line = pe.Line
+		} else if err == nil {
+			line, _ = it.r.FieldPos(0)
+		}
+		if err == nil {
+			var v reflect.Value
+			if v, err = it.decode(rec); err == nil {
+				return v.Interface(), true
+			}
+		}
+		if it.onReject == nil {
+			it.err = &RecordError{Line: line, Err: err}
+			return nil, false
+		}
+		it.onReject(Reject{Line: line, Raw: joinCSV(rec, it.r.Comma), Err: err})
+	}
+}
+
+func (it *csvIter) Err() error { return it.err }
+
+func (it *csvIter) ElemType() reflect.Type { return elemType(it.t, it.ptr) }
+
+// readHeader maps each column onto the struct field it decodes into
+func (it *csvIter) readHeader() bool {
+	header := it.header
+	if len(header) == 0 {
+		rec, err := it.r.Read()
+		if err == io.EOF {
+			return false
+		}
+		if err != nil {
+			it.err = err
+			return false
+		}
+		header = rec
+	}
+
+	cols := columns(it.t, "csv")
+	it.fields = make([][]int, len(header))
+	for i, name := range header {
+		for _, c := range cols {
+			if strings.EqualFold(strings.TrimSpace(name), c.name) {
+				it.fields[i] = c.index
+				break
+			}
+		}
+	}
+	return true
+}
+
+func (it *csvIter) decode(rec []string) (reflect.Value, error) {
+	p := reflect.New(it.t)
+	if len(rec) != len(it.fields) {
+		return p, fmt.Errorf("record has %d fields, want %d", len(rec), len(it.fields))
+	}
+	for i, s := range rec {
+		if it.fields[i] == nil {
+			continue
+		}
+		if err := parse(p.Elem().FieldByIndex(it.fields[i]), s); err != nil {
+			return p, fmt.Errorf("column %d: %v", i+1, err)
+		}
+	}
+	if it.ptr {
+		return p, nil
+	}
+	return p.Elem(), nil
+}
+
+func joinCSV(rec []string, comma rune) string {
+	b := &bytes.Buffer{}
+	w := csv.NewWriter(b)
+	w.Comma = comma
+	w.Write(rec)
+	w.Flush()
+	return strings.TrimRight(b.String(), "\r\n")
+}
+
+// WriteCSV writes the struct elements of `it` to `w` as CSV rows after a
+// header row named by `csv` tags or field names
+func WriteCSV(w *csv.Writer, it iterators.Iterator) error {
+	var t reflect.Type
+	var cols []column
+	for e, ok := it.Next(); ok; e, ok = it.Next() {
+		v, err := record(e, &t)
+		if err != nil {
+			return err
+		}
+		if cols == nil {
+			cols = columns(t, "csv")
+			header := make([]string, len(cols))
+			for i, c := range cols {
+				header[i] = c.name
+			}
+			if err := w.Write(header); err != nil {
+				return err
+			}
+		}
+
+		row := make([]string, len(cols))
+		for i, c := range cols {
+			if row[i], err = format(v.FieldByIndex(c.index)); err != nil {
+				return err
+			}
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	if err := iterators.Err(it); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
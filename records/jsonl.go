@@ -0,0 +1,87 @@
+package records
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+
+	"gitlab.com/jdbellamy/kundalini/iterators"
+)
+
+type jsonLinesIter struct {
+	r        *bufio.Reader
+	t        reflect.Type
+	ptr      bool
+	line     int
+	onReject func(Reject)
+	err      error
+}
+
+// JSONLines decodes each line of `r` into a struct typed like `prototype`
+// blank lines are skipped, lines that cannot be decoded are passed to
+// `onReject`, or stop the iterator with a `*RecordError` when it is nil
+func JSONLines(r io.Reader, prototype interface{}, onReject func(Reject)) iterators.ErrIterator {
+	t, ptr, err := recordType(prototype)
+	if err != nil {
+		return iterators.Failed(err)
+	}
+	return &jsonLinesIter{r: bufio.NewReader(r), t: t, ptr: ptr, onReject: onReject}
+}
+
+func (it *jsonLinesIter) Next() (interface{}, bool) {
+	for it.err == nil {
+		b, err := it.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			it.err = err
+			return nil, false
+		}
+		if len(b) == 0 && err == io.EOF {
+			return nil, false
+		}
+		it.line++
+
+		b = bytes.TrimSpace(b)
+		if len(b) == 0 {
+			continue
+		}
+		p := reflect.New(it.t)
+		if jerr := json.Unmarshal(b, p.Interface()); jerr != nil {
+			if it.onReject == nil {
+				it.err = &RecordError{Line: it.line, Err: jerr}
+				return nil, false
+			}
+			it.onReject(Reject{Line: it.line, Raw: string(b), Err: jerr})
+			continue
+		}
+		if it.ptr {
+			return p.Interface(), true
+		}
+		return p.Elem().Interface(), true
+	}
+	return nil, false
+}
+
+func (it *jsonLinesIter) Err() error { return it.err }
+
+func (it *jsonLinesIter) ElemType() reflect.Type { return elemType(it.t, it.ptr) }
+
+// WriteJSONLines writes each struct element of `it` to `w` as a line of JSON
+func WriteJSONLines(w io.Writer, it iterators.Iterator) error {
+	var t reflect.Type
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for e, ok := it.Next(); ok; e, ok = it.Next() {
+		if _, err := record(e, &t); err != nil {
+			return err
+		}
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	if err := iterators.Err(it); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
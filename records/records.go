@@ -0,0 +1,190 @@
+package records
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/jdbellamy/kundalini/slices"
+)
+
+var UnsupportedFieldTypeError = fmt.Errorf("field type cannot be decoded from text")
+var MixedRecordTypesError = fmt.Errorf("records must all have the same type")
+
+// Reject is a record that could not be decoded
+type Reject struct {
+	Line int
+	Raw  string
+	Err  error
+}
+
+// RecordError reports the record that stopped a source
+type RecordError struct {
+	Line int
+	Err  error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the error the record failed with so that `errors.Is` matches it
+func (e *RecordError) Unwrap() error { return e.Err }
+
+// recordType resolves the struct type records are decoded into
+// records are yielded as pointers when `prototype` is a pointer
+func recordType(prototype interface{}) (reflect.Type, bool, error) {
+	t := reflect.TypeOf(prototype)
+	if t == nil {
+		return nil, false, slices.NotAStructError
+	}
+	ptr := t.Kind() == reflect.Ptr
+	if ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false, slices.NotAStructError
+	}
+	return t, ptr, nil
+}
+
+// elemType is the type of the records decoded into `t`
+func elemType(t reflect.Type, ptr bool) reflect.Type {
+	if ptr {
+		return reflect.PtrTo(t)
+	}
+	return t
+}
+
+// column is an exported struct field and the name it is written under
+type column struct {
+	name  string
+	index []int
+}
+
+// columns lists the exported fields of `t` named by their `tag` or field name
+func columns(t reflect.Type, tag string) []column {
+	cols := make([]column, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.Split(sf.Tag.Get(tag), ",")[0]
+		if sf.PkgPath != "" || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		cols = append(cols, column{name: name, index: sf.Index})
+	}
+	return cols
+}
+
+var durationT = reflect.TypeOf(time.Duration(0))
+
+// parse sets `v` from its text form `s`, an empty `s` leaves the zero value
+func parse(v reflect.Value, s string) error {
+	if s == "" {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		p := reflect.New(v.Type().Elem())
+		if err := parse(p.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationT {
+		d, err := time.ParseDuration(s)
+		v.SetInt(int64(d))
+		return err
+	}
+
+	switch k := v.Kind(); {
+	case k == reflect.String:
+		v.SetString(s)
+	case k == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case k >= reflect.Int && k <= reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case k >= reflect.Uint && k <= reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case k == reflect.Float32 || k == reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return UnsupportedFieldTypeError
+	}
+	return nil
+}
+
+// format returns the text form of `v`, a nil pointer is empty
+func format(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+	if v.Type() == durationT {
+		return time.Duration(v.Int()).String(), nil
+	}
+
+	switch k := v.Kind(); {
+	case k == reflect.String:
+		return v.String(), nil
+	case k == reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case k >= reflect.Int && k <= reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case k >= reflect.Uint && k <= reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case k == reflect.Float32 || k == reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", UnsupportedFieldTypeError
+}
+
+// record returns the struct held by the element `e`
+// every element must share the type of the first
+func record(e interface{}, t *reflect.Type) (reflect.Value, error) {
+	v := reflect.ValueOf(e)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return v, slices.NotAStructError
+	}
+	if *t == nil {
+		*t = v.Type()
+	}
+	if v.Type() != *t {
+		return v, MixedRecordTypesError
+	}
+	return v, nil
+}
@@ -0,0 +1,187 @@
+package records_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "gitlab.com/jdbellamy/kundalini"
+	"gitlab.com/jdbellamy/kundalini/records"
+	"gitlab.com/jdbellamy/kundalini/slices"
+)
+
+type Order struct {
+	ID      int           `csv:"id" json:"id"`
+	Item    string        `csv:"item" json:"item"`
+	Price   float64       `csv:"price" json:"price"`
+	Paid    bool          `csv:"paid" json:"paid"`
+	Wait    time.Duration `csv:"wait" json:"wait"`
+	Note    *string       `csv:"note" json:"note,omitempty"`
+	Ignored string        `csv:"-" json:"-"`
+}
+
+const ordersCSV = `id,item,price,paid,wait,note
+1,tea,3.5,true,2m,
+2,cake,4,false,30s,no nuts
+`
+
+func TestFromCSV_DecodesRowsIntoStructs(t *testing.T) {
+	buf := []Order{}
+	_, err := FromCSV(strings.NewReader(ordersCSV), Order{}, CSVOptions{}).
+		Export(reflect.ValueOf(&buf)).
+		Release()
+	note := "no nuts"
+	expected := []Order{
+		{ID: 1, Item: "tea", Price: 3.5, Paid: true, Wait: 2 * time.Minute},
+		{ID: 2, Item: "cake", Price: 4, Wait: 30 * time.Second, Note: &note},
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, buf)
+}
+
+func TestFromCSV_PointerPrototypeAndColumnsWithoutHeader(t *testing.T) {
+	in := "tea;1\ncake;2\n"
+	actual, err := FromCSV(strings.NewReader(in), &Order{}, CSVOptions{
+		Columns: []string{"ITEM", "ID"},
+		Comma:   ';',
+	}).Release()
+	expected := []*Order{{ID: 1, Item: "tea"}, {ID: 2, Item: "cake"}}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestFromCSV_BadRowBecomesChainError(t *testing.T) {
+	in := "id,item\n1,tea\nx,cake\n3,pie\n"
+	actual, err := FromCSV(strings.NewReader(in), Order{}, CSVOptions{}).Release()
+	assert.EqualError(t, err, `line 3: column 1: strconv.ParseInt: parsing "x": invalid syntax`)
+	assert.IsType(t, &records.RecordError{}, err)
+	assert.Nil(t, actual)
+}
+
+func TestFromCSV_BadRowsGoToRejectSink(t *testing.T) {
+	in := "id,item\n1,tea\nx,cake\n3\n4,pie\n"
+	rejects := []Reject{}
+	actual, err := FromCSV(strings.NewReader(in), Order{}, CSVOptions{
+		OnReject: func(r Reject) { rejects = append(rejects, r) },
+	}).Map(func(x interface{}) interface{} { return x.(Order).ID }).Release()
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{1, 4}, actual)
+	if assert.Len(t, rejects, 2) {
+		assert.Equal(t, 3, rejects[0].Line)
+		assert.Equal(t, "x,cake", rejects[0].Raw)
+		assert.Equal(t, 4, rejects[1].Line)
+		assert.EqualError(t, rejects[1].Err, "record has 1 fields, want 2")
+	}
+}
+
+func TestFromCSV_MalformedRowBecomesChainError(t *testing.T) {
+	in := "id,item\n1,tea\n2,b\"x\n3,pie\n"
+	actual, err := FromCSV(strings.NewReader(in), Order{}, CSVOptions{}).Release()
+	assert.Error(t, err)
+	assert.Equal(t, 3, err.(*records.RecordError).Line)
+	assert.ErrorIs(t, err, csv.ErrBareQuote)
+	assert.Nil(t, actual)
+}
+
+func TestFromCSV_MalformedRowGoesToRejectSink(t *testing.T) {
+	in := "id,item\n1,tea\n2,b\"x\n3,pie\n"
+	rejects := []Reject{}
+	actual, err := FromCSV(strings.NewReader(in), Order{}, CSVOptions{
+		OnReject: func(r Reject) { rejects = append(rejects, r) },
+	}).Pluck("Item").Release()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tea", "pie"}, actual)
+	if assert.Len(t, rejects, 1) {
+		assert.Equal(t, 3, rejects[0].Line)
+	}
+}
+
+func TestFromCSV_ChainsIntoFieldOperations(t *testing.T) {
+	actual, err := FromCSV(strings.NewReader(ordersCSV), Order{}, CSVOptions{}).
+		WhereField("paid", false).
+		Pluck("item").
+		Release()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cake"}, actual)
+}
+
+func TestFromCSV_NotAStructError(t *testing.T) {
+	actual, err := FromCSV(strings.NewReader(ordersCSV), 0, CSVOptions{}).Release()
+	assert.EqualError(t, err, slices.NotAStructError.Error())
+	assert.Nil(t, actual)
+}
+
+func TestFromJSONLines_DecodesLinesIntoStructs(t *testing.T) {
+	in := `{"id": 1, "item": "tea", "price": 3.5}
+
+{"id": 2, "item": "cake", "paid": true}
+`
+	actual, err := FromJSONLines(strings.NewReader(in), Order{}, nil).Release()
+	expected := []Order{
+		{ID: 1, Item: "tea", Price: 3.5},
+		{ID: 2, Item: "cake", Paid: true},
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestFromJSONLines_BadLineBecomesChainError(t *testing.T) {
+	in := "{\"id\": 1}\n{\"id\": \"one\"}\n"
+	actual, err := FromJSONLines(strings.NewReader(in), Order{}, nil).Release()
+	assert.Error(t, err)
+	assert.Equal(t, 2, err.(*records.RecordError).Line)
+	assert.Nil(t, actual)
+}
+
+func TestFromJSONLines_BadLinesGoToRejectSink(t *testing.T) {
+	in := "{\"id\": 1}\nnot json\n{\"id\": 3}"
+	rejects := []Reject{}
+	actual, err := FromJSONLines(strings.NewReader(in), &Order{}, func(r Reject) {
+		rejects = append(rejects, r)
+	}).Release()
+	assert.NoError(t, err)
+	assert.Equal(t, []*Order{{ID: 1}, {ID: 3}}, actual)
+	if assert.Len(t, rejects, 1) {
+		assert.Equal(t, 2, rejects[0].Line)
+		assert.Equal(t, "not json", rejects[0].Raw)
+	}
+}
+
+func TestToCSV_RoundTrips(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := FromCSV(strings.NewReader(ordersCSV), Order{}, CSVOptions{}).ToCSV(out)
+	expected := `id,item,price,paid,wait,note
+1,tea,3.5,true,2m0s,
+2,cake,4,false,30s,no nuts
+`
+	assert.NoError(t, err)
+	assert.Equal(t, expected, out.String())
+}
+
+func TestToCSV_MixedRecordTypesError(t *testing.T) {
+	type Other struct{ ID int }
+	err := Wrap([]interface{}{Order{}, Other{}}).ToCSV(&bytes.Buffer{})
+	assert.EqualError(t, err, records.MixedRecordTypesError.Error())
+}
+
+func TestToJSONLines_WritesOneRecordPerLine(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := Wrap([]Order{{ID: 1, Item: "tea"}, {ID: 2}}).ToJSONLines(out)
+	expected := `{"id":1,"item":"tea","price":0,"paid":false,"wait":0}
+{"id":2,"item":"","price":0,"paid":false,"wait":0}
+`
+	assert.NoError(t, err)
+	assert.Equal(t, expected, out.String())
+}
+
+func TestToJSONLines_ForwardsReceivedError(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := Wrap(0).Types().ToJSONLines(out)
+	assert.EqualError(t, err, UnsupportedWrappedTypeError.Error())
+	assert.Equal(t, "", out.String())
+}
@@ -0,0 +1,71 @@
+package kundalini
+
+import (
+	"fmt"
+)
+
+var UnknownFnError = fmt.Errorf("unknown Fn")
+var UnknownPredicateError = fmt.Errorf("unknown Predicate")
+var UnknownTransformError = fmt.Errorf("unknown Transform")
+
+// Registry holds the named `Fn`s, `Predicate`s and `Transform`s that a
+// declarative pipeline spec can refer to
+type Registry struct {
+	fns        map[string]Fn
+	predicates map[string]Predicate
+	transforms map[string]Transform
+}
+
+// NewRegistry returns an empty `Registry`
+func NewRegistry() *Registry {
+	return &Registry{
+		fns:        make(map[string]Fn),
+		predicates: make(map[string]Predicate),
+		transforms: make(map[string]Transform),
+	}
+}
+
+// RegisterFn makes `fn` available to `map` stages as `name`
+func (r *Registry) RegisterFn(name string, fn Fn) *Registry {
+	r.fns[name] = fn
+	return r
+}
+
+// RegisterPredicate makes `p` available to `filter` stages as `name`
+func (r *Registry) RegisterPredicate(name string, p Predicate) *Registry {
+	r.predicates[name] = p
+	return r
+}
+
+// RegisterTransform makes `fn` available to `reduce` stages as `name`
+func (r *Registry) RegisterTransform(name string, fn Transform) *Registry {
+	r.transforms[name] = fn
+	return r
+}
+
+// Fn looks up the `Fn` registered as `name`
+func (r *Registry) Fn(name string) (Fn, error) {
+	fn, ok := r.fns[name]
+	if !ok {
+		return nil, UnknownFnError
+	}
+	return fn, nil
+}
+
+// Predicate looks up the `Predicate` registered as `name`
+func (r *Registry) Predicate(name string) (Predicate, error) {
+	p, ok := r.predicates[name]
+	if !ok {
+		return nil, UnknownPredicateError
+	}
+	return p, nil
+}
+
+// Transform looks up the `Transform` registered as `name`
+func (r *Registry) Transform(name string) (Transform, error) {
+	fn, ok := r.transforms[name]
+	if !ok {
+		return nil, UnknownTransformError
+	}
+	return fn, nil
+}
@@ -0,0 +1,142 @@
+package kundalini
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/iterators"
+)
+
+var StageTimeoutError = fmt.Errorf("stage timed out")
+
+// StageOption configures how a stage calls its function and keeps time
+type StageOption func(*stageConfig)
+
+type stageConfig struct {
+	retries int
+	backoff time.Duration
+	timeout time.Duration
+	clock   Clock
+	mark    iterators.Watermark
+}
+
+// WithRetry re-runs a failing element up to `n` more times, waiting `backoff`
+// before the first retry and doubling the wait after each, with jitter
+// the error of the last attempt is the one reported
+func WithRetry(n int, backoff time.Duration) StageOption {
+	return func(c *stageConfig) {
+		c.retries = n
+		c.backoff = backoff
+	}
+}
+
+// WithTimeout fails each attempt that takes longer than `d` with
+// `StageTimeoutError`, the abandoned call is left to finish in the background
+// timeouts are measured in real time whatever the `Clock`
+func WithTimeout(d time.Duration) StageOption {
+	return func(c *stageConfig) {
+		c.timeout = d
+	}
+}
+
+// WithClock makes a stage wait and tell the time with `c`
+func WithClock(c Clock) StageOption {
+	return func(cfg *stageConfig) {
+		cfg.clock = c
+	}
+}
+
+func newStageConfig(opts []StageOption) *stageConfig {
+	c := &stageConfig{clock: SystemClock}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// withOptions applys `opts` around `fn`
+func withOptions(fn FnE, opts []StageOption) FnE {
+	c := newStageConfig(opts)
+	if c.timeout > 0 {
+		fn = timeout(fn, c.timeout)
+	}
+	if c.retries > 0 {
+		fn = retry(fn, c.retries, c.backoff, c.clock)
+	}
+	return fn
+}
+
+// predicateOptions applys `opts` around `p`
+func predicateOptions(p PredicateE, opts []StageOption) PredicateE {
+	if len(opts) == 0 {
+		return p
+	}
+	fn := withOptions(func(v interface{}) (interface{}, error) {
+		return p(v)
+	}, opts)
+	return func(v interface{}) (bool, error) {
+		ok, err := fn(v)
+		if err != nil {
+			return false, err
+		}
+		return ok.(bool), nil
+	}
+}
+
+func retry(fn FnE, n int, backoff time.Duration, clock Clock) FnE {
+	return func(v interface{}) (interface{}, error) {
+		wait := backoff
+		for attempt := 0; ; attempt++ {
+			r, err := fn(v)
+			if err == nil || attempt == n {
+				return r, err
+			}
+			logrus.Debug(" retry: ", err)
+			clock.Sleep(jitter(wait))
+			wait *= 2
+		}
+	}
+}
+
+// jitter returns a random duration between half of `d` and `d`
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func timeout(fn FnE, d time.Duration) FnE {
+	type result struct {
+		v     interface{}
+		err   error
+		panic interface{}
+	}
+	return func(v interface{}) (interface{}, error) {
+		done := make(chan result, 1)
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					done <- result{panic: r}
+				}
+			}()
+			r, err := fn(v)
+			done <- result{v: r, err: err}
+		}()
+
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case r := <-done:
+			if r.panic != nil {
+				panic(r.panic)
+			}
+			return r.v, r.err
+		case <-timer.C:
+			logrus.Debug("expire: ", v)
+			return nil, StageTimeoutError
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package kundalini_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+)
+
+// flaky fails each element `n` times before succeeding
+func flaky(n int32) (FnE, *int32) {
+	calls := new(int32)
+	fails := make(map[interface{}]int32)
+	return func(x interface{}) (interface{}, error) {
+		atomic.AddInt32(calls, 1)
+		if fails[x] < n {
+			fails[x]++
+			return nil, errors.New("unavailable")
+		}
+		return x.(int) * 10, nil
+	}, calls
+}
+
+func TestWithRetry(t *testing.T) {
+
+	t.Run("should re-run failing elements", func(t *testing.T) {
+		fn, calls := flaky(2)
+
+		actual, err := Wrap([]int{1, 2}).MapE(fn, WithRetry(2, time.Millisecond)).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{10, 20}, actual)
+		assert.Equal(t, int32(6), *calls)
+	})
+
+	t.Run("should report the error of the last attempt", func(t *testing.T) {
+		fn, calls := flaky(5)
+
+		_, err := Wrap([]int{1}).MapE(fn, WithRetry(2, time.Millisecond)).Release()
+
+		assert.EqualError(t, err, "stage 0: unavailable")
+		assert.Equal(t, int32(3), *calls)
+	})
+
+	t.Run("should back off exponentially with jitter", func(t *testing.T) {
+		fn, _ := flaky(3)
+		clock := &fakeClock{}
+
+		_, err := Wrap([]int{1}).MapE(fn, WithRetry(3, 8*time.Millisecond), WithClock(clock)).Release()
+
+		assert.NoError(t, err)
+		if assert.Len(t, clock.slept, 3) {
+			for i, wait := range []time.Duration{8, 16, 32} {
+				assert.True(t, clock.slept[i] >= wait*time.Millisecond/2)
+				assert.True(t, clock.slept[i] <= wait*time.Millisecond)
+			}
+		}
+	})
+
+	t.Run("should retry predicates", func(t *testing.T) {
+		tries := 0
+		p := func(x interface{}) (bool, error) {
+			if tries++; tries < 3 {
+				return false, errors.New("unavailable")
+			}
+			return true, nil
+		}
+
+		actual, err := Wrap([]int{1}).FilterE(p, WithRetry(2, time.Millisecond)).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1}, actual)
+	})
+}
+
+func TestWithTimeout(t *testing.T) {
+	slow := func(x interface{}) (interface{}, error) {
+		time.Sleep(time.Duration(x.(int)) * time.Millisecond)
+		return x, nil
+	}
+
+	t.Run("should fail slow elements", func(t *testing.T) {
+		_, err := Wrap([]int{1, 200}).MapE(slow, WithTimeout(50*time.Millisecond)).Release()
+
+		if r, ok := err.(*Rejection); assert.True(t, ok) {
+			assert.Equal(t, 200, r.Value)
+			assert.Equal(t, StageTimeoutError, r.Err)
+		}
+		assert.ErrorIs(t, err, StageTimeoutError)
+	})
+
+	t.Run("should reject slow elements in dead-letter mode", func(t *testing.T) {
+		k := Wrap([]int{1, 200, 2}).DeadLetter().MapE(slow, WithTimeout(50*time.Millisecond), WithRetry(1, time.Millisecond))
+
+		actual, err := k.Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, actual)
+		assert.Equal(t, []Rejection{{Stage: 0, Value: 200, Err: StageTimeoutError}}, k.Rejected())
+	})
+
+	t.Run("should pass on panics", func(t *testing.T) {
+		boom := func(x interface{}) (interface{}, error) { panic("boom") }
+
+		assert.PanicsWithValue(t, "boom", func() {
+			Wrap([]int{1}).MapE(boom, WithTimeout(time.Second))
+		})
+	})
+}
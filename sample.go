@@ -0,0 +1,85 @@
+package kundalini
+
+import (
+	"math/rand"
+	"reflect"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/iterators"
+	"gitlab.com/jdbellamy/kundalini/slices"
+)
+
+// Less reports whether `a` orders before `b`
+type Less func(a, b interface{}) bool
+
+// TopK keeps the `n` greatest elements of `k` by `less`, greatest first
+// equal elements keep their order, only `n` elements are held at a time so a
+// lazy `k` is never collected
+func (k *K) TopK(n int, less Less) Kundalini {
+	if k.err != nil {
+		return k
+	}
+	if n < 0 {
+		return k.fail("TopK", slices.NegativeCountError)
+	}
+	if it, ok := k.source(); ok {
+		v := slices.TopKOf(it.Next, n, less)
+		logrus.Debug("  topk: ", v)
+		if err := iterators.Err(it); err != nil {
+			return k.fail("TopK", err)
+		}
+		return k.next("TopK", less, v)
+	}
+	switch reflect.TypeOf(k.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		v, _ := slices.TopK(reflect.ValueOf(k.wrapped), n, less)
+		logrus.Debug("  topk: ", v)
+		return k.next("TopK", less, v)
+	}
+	return k.fail("TopK", UnsupportedWrappedTypeError)
+}
+
+// Sample keeps `n` elements of `k` chosen uniformly at random by reservoir
+// sampling, or all of them when there are fewer
+// the same `seed` picks the same elements from the same input, only `n`
+// elements are held at a time so a lazy `k` is never collected
+func (k *K) Sample(n int, seed int64) Kundalini {
+	if k.err != nil {
+		return k
+	}
+	if n < 0 {
+		return k.fail("Sample", slices.NegativeCountError)
+	}
+	rng := rand.New(rand.NewSource(seed))
+	if it, ok := k.source(); ok {
+		v := slices.SampleOf(it.Next, n, rng)
+		logrus.Debug("sample: ", v)
+		if err := iterators.Err(it); err != nil {
+			return k.fail("Sample", err)
+		}
+		return k.next("Sample", nil, v)
+	}
+	switch reflect.TypeOf(k.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		v, _ := slices.Sample(reflect.ValueOf(k.wrapped), n, rng)
+		logrus.Debug("sample: ", v)
+		return k.next("Sample", nil, v)
+	}
+	return k.fail("Sample", UnsupportedWrappedTypeError)
+}
+
+// Shuffle puts the elements of `k` in a random order, the same `seed` gives
+// the same order for the same input
+func (k *K) Shuffle(seed int64) Kundalini {
+	k = k.drain()
+	if k.err != nil {
+		return k
+	}
+	switch reflect.TypeOf(k.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		v := slices.Shuffle(reflect.ValueOf(k.wrapped), rand.New(rand.NewSource(seed)))
+		logrus.Debug("shufle: ", v)
+		return k.next("Shuffle", nil, v)
+	}
+	return k.fail("Shuffle", UnsupportedWrappedTypeError)
+}
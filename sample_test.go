@@ -0,0 +1,135 @@
+package kundalini_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+	"gitlab.com/jdbellamy/kundalini/slices"
+)
+
+func byInt(a, b interface{}) bool { return a.(int) < b.(int) }
+
+func TestTopK(t *testing.T) {
+
+	t.Run("should keep the greatest elements, greatest first", func(t *testing.T) {
+		type Test struct {
+			input    interface{}
+			n        int
+			expected interface{}
+		}
+		tests := []Test{
+			{[]int{5, 1, 9, 3, 7}, 3, []int{9, 7, 5}},
+			{[]int{5, 1}, 3, []int{5, 1}},
+			{[]int{5, 1}, 0, []int{}},
+			{[3]int{2, 8, 4}, 2, []int{8, 4}},
+			{[]interface{}{4, 6, 2}, 1, []interface{}{6}},
+		}
+		for _, test := range tests {
+			actual, err := Wrap(test.input).TopK(test.n, byInt).Release()
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		}
+	})
+
+	t.Run("should keep equal elements in input order", func(t *testing.T) {
+		byAt := func(a, b interface{}) bool { return a.(Event).At < b.(Event).At }
+		input := []Event{{1, "a"}, {3, "b"}, {2, "c"}, {3, "d"}, {3, "e"}}
+		actual, err := Wrap(input).TopK(3, byAt).Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []Event{{3, "b"}, {3, "d"}, {3, "e"}}, actual)
+	})
+
+	t.Run("should stream a lazy source", func(t *testing.T) {
+		actual, err := Wrap(&counter{to: 1000}).TopK(2, byInt).Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{999, 998}, actual)
+	})
+
+	t.Run("should reject a negative count", func(t *testing.T) {
+		actual, err := Wrap([]int{1}).TopK(-1, byInt).Release()
+		assert.EqualError(t, err, slices.NegativeCountError.Error())
+		assert.Nil(t, actual)
+	})
+}
+
+func TestSample(t *testing.T) {
+
+	t.Run("should pick n elements reproducibly", func(t *testing.T) {
+		input := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+		first, err := Wrap(input).Sample(4, 42).Release()
+		assert.NoError(t, err)
+		assert.Len(t, first, 4)
+		for _, x := range first.([]int) {
+			assert.Contains(t, input, x)
+		}
+
+		var again []int
+		_, err = Wrap(&counter{to: 10}).Sample(4, 42).Export(reflect.ValueOf(&again)).Release()
+		assert.NoError(t, err)
+		assert.Equal(t, first, again)
+	})
+
+	t.Run("should pick every element with equal chance", func(t *testing.T) {
+		hits := make([]int, 10)
+		for seed := int64(0); seed < 2000; seed++ {
+			v, _ := Wrap(&counter{to: 10}).Sample(3, seed).Release()
+			for _, x := range v.([]interface{}) {
+				hits[x.(int)]++
+			}
+		}
+		for i, n := range hits {
+			assert.InDelta(t, 600, n, 90, "element %d", i)
+		}
+	})
+
+	t.Run("should keep every element of a short input", func(t *testing.T) {
+		actual, err := Wrap([]string{"a", "b"}).Sample(5, 1).Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, actual)
+	})
+
+	t.Run("should reject a negative count", func(t *testing.T) {
+		actual, err := Wrap(&counter{to: 3}).Sample(-1, 1).Release()
+		assert.EqualError(t, err, slices.NegativeCountError.Error())
+		assert.Nil(t, actual)
+	})
+}
+
+func TestShuffle(t *testing.T) {
+
+	t.Run("should permute the elements reproducibly", func(t *testing.T) {
+		type Test struct {
+			input interface{}
+		}
+		tests := []Test{
+			{[]int{1, 2, 3, 4, 5, 6, 7, 8}},
+			{[4]string{"a", "b", "c", "d"}},
+			{&counter{to: 8}},
+		}
+		for _, test := range tests {
+			first, err := Wrap(test.input).Shuffle(7).Release()
+			assert.NoError(t, err)
+			if c, ok := test.input.(*counter); ok {
+				c.from = 0
+			}
+			again, _ := Wrap(test.input).Shuffle(7).Release()
+			assert.Equal(t, first, again)
+		}
+
+		v, _ := Wrap([]int{1, 2, 3, 4, 5, 6, 7, 8}).Shuffle(7).Release()
+		shuffled := append([]int(nil), v.([]int)...)
+		assert.NotEqual(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, shuffled)
+		sort.Ints(shuffled)
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, shuffled)
+	})
+
+	t.Run("should not modify the input", func(t *testing.T) {
+		input := []int{1, 2, 3}
+		_, err := Wrap(input).Shuffle(1).Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, input)
+	})
+}
@@ -0,0 +1,101 @@
+package kundalini
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TypeInfo is a JSON friendly description of a `reflect.Type`
+type TypeInfo struct {
+	Kind    string      `json:"kind"`
+	Type    string      `json:"type"`
+	Name    string      `json:"name,omitempty"`
+	PkgPath string      `json:"pkgPath,omitempty"`
+	Len     int         `json:"len,omitempty"`
+	Key     *TypeInfo   `json:"key,omitempty"`
+	Elem    *TypeInfo   `json:"elem,omitempty"`
+	Fields  []FieldInfo `json:"fields,omitempty"`
+}
+
+// FieldInfo describes an exported struct field
+type FieldInfo struct {
+	Name string    `json:"name"`
+	Tag  string    `json:"tag,omitempty"`
+	Type *TypeInfo `json:"type"`
+}
+
+var typeT = reflect.TypeOf((*reflect.Type)(nil)).Elem()
+
+// TypeInfoOf describes `t` along with its key, element and exported field types
+// a named type that refers to itself is only described in full once
+// a nil `t`, as reported by `Types` for nil elements, describes as nil
+func TypeInfoOf(t reflect.Type) *TypeInfo {
+	return typeInfo(t, make(map[reflect.Type]bool))
+}
+
+func typeInfo(t reflect.Type, seen map[reflect.Type]bool) *TypeInfo {
+	if t == nil {
+		return nil
+	}
+	info := &TypeInfo{
+		Kind:    t.Kind().String(),
+		Type:    t.String(),
+		Name:    t.Name(),
+		PkgPath: t.PkgPath(),
+	}
+	if seen[t] {
+		return info
+	}
+	if t.Name() != "" {
+		seen[t] = true
+		defer delete(seen, t)
+	}
+
+	switch t.Kind() {
+	case reflect.Array:
+		info.Len = t.Len()
+		info.Elem = typeInfo(t.Elem(), seen)
+	case reflect.Slice, reflect.Ptr, reflect.Chan:
+		info.Elem = typeInfo(t.Elem(), seen)
+	case reflect.Map:
+		info.Key = typeInfo(t.Key(), seen)
+		info.Elem = typeInfo(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+			info.Fields = append(info.Fields, FieldInfo{
+				Name: sf.Name,
+				Tag:  string(sf.Tag),
+				Type: typeInfo(sf.Type, seen),
+			})
+		}
+	}
+	return info
+}
+
+// MarshalJSON encodes the elements wrapped by `k`, the `reflect.Type`s
+// produced by `Types` are encoded as `TypeInfo`s
+// a chain holding an error fails to encode with that error
+func (k *K) MarshalJSON() ([]byte, error) {
+	val, err := k.Release()
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debug("  json: ", val)
+
+	v := reflect.ValueOf(val)
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem() == typeT {
+		infos := make([]*TypeInfo, v.Len())
+		for i := range infos {
+			t, _ := v.Index(i).Interface().(reflect.Type)
+			infos[i] = TypeInfoOf(t)
+		}
+		return json.Marshal(infos)
+	}
+	return json.Marshal(val)
+}
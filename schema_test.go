@@ -0,0 +1,90 @@
+package kundalini_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+)
+
+type Node struct {
+	Label string `json:"label"`
+	Next  *Node
+	score int
+}
+
+func TestMarshalJSON(t *testing.T) {
+
+	t.Run("should encode the released value", func(t *testing.T) {
+		double := func(x interface{}) interface{} { return x.(int) * 2 }
+
+		actual, err := json.Marshal(Wrap([]int{1, 2}).Map(double))
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[2, 4]`, string(actual))
+	})
+
+	t.Run("should encode a response holding a result and its types", func(t *testing.T) {
+		k := Wrap([]interface{}{1, "a", nil})
+		response := map[string]Kundalini{
+			"result": k,
+			"types":  k.Types(),
+		}
+
+		actual, err := json.Marshal(response)
+
+		expected := `{
+			"result": [1, "a", null],
+			"types": [
+				{"kind": "int", "type": "int", "name": "int"},
+				{"kind": "string", "type": "string", "name": "string"},
+				null
+			]
+		}`
+		assert.NoError(t, err)
+		assert.JSONEq(t, expected, string(actual))
+	})
+
+	t.Run("should fail with received error", func(t *testing.T) {
+		actual, err := json.Marshal(Wrap(0).Types())
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), UnsupportedWrappedTypeError.Error())
+		assert.Nil(t, actual)
+	})
+}
+
+func TestTypeInfoOf(t *testing.T) {
+
+	t.Run("should describe element, key and field types", func(t *testing.T) {
+		actual := TypeInfoOf(reflect.TypeOf(map[string][2]Node{}))
+
+		assert.Equal(t, "map", actual.Kind)
+		assert.Equal(t, "string", actual.Key.Kind)
+		assert.Equal(t, 2, actual.Elem.Len)
+		node := actual.Elem.Elem
+		assert.Equal(t, "Node", node.Name)
+		assert.Equal(t, "gitlab.com/jdbellamy/kundalini_test", node.PkgPath)
+		if assert.Len(t, node.Fields, 2) {
+			assert.Equal(t, FieldInfo{Name: "Label", Tag: `json:"label"`, Type: TypeInfoOf(reflect.TypeOf(""))}, node.Fields[0])
+			assert.Equal(t, "ptr", node.Fields[1].Type.Kind)
+		}
+	})
+
+	t.Run("should stop at recursive types", func(t *testing.T) {
+		actual := TypeInfoOf(reflect.TypeOf(Node{}))
+
+		next := actual.Fields[1].Type.Elem
+		assert.Equal(t, "Node", next.Name)
+		assert.Nil(t, next.Fields)
+
+		_, err := json.Marshal(actual)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should describe nil as nil", func(t *testing.T) {
+		assert.Nil(t, TypeInfoOf(nil))
+	})
+}
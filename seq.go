@@ -0,0 +1,92 @@
+//go:build go1.23
+
+package kundalini
+
+import (
+	"iter"
+	"reflect"
+
+	"gitlab.com/jdbellamy/kundalini/iterators"
+)
+
+// All returns the elements of `k` as an `iter.Seq` for use in `for range`
+// loops and with the standard library's iterator helpers
+// it panics with the error of `k` or of its source, like `ReleaseOrPanic`,
+// once the elements before the error have been yielded, use `Iterator` to
+// handle errors without panicking
+// a loop that stops early closes `k` when it is a `Closer`
+func (k *K) All() iter.Seq[interface{}] {
+	return All(k)
+}
+
+// All is `(*K).All` for any `Kundalini`, whose interface cannot declare it
+// while builds before go1.23 are supported
+func All(k Kundalini) iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		it := k.Iterator()
+		for v, ok := it.Next(); ok; v, ok = it.Next() {
+			if !yield(v) {
+				iterators.Close(it)
+				return
+			}
+		}
+		if e, ok := it.(ErrIterator); ok && e.Err() != nil {
+			panic(e.Err())
+		}
+	}
+}
+
+// pulled adapts a push style `iter.Seq` to an `Iterator`
+type pulled struct {
+	next func() (interface{}, bool)
+	stop func()
+}
+
+func (p *pulled) Next() (interface{}, bool) {
+	v, ok := p.next()
+	if !ok {
+		p.stop()
+	}
+	return v, ok
+}
+
+// Close stops the sequence and its goroutine
+func (p *pulled) Close() { p.stop() }
+
+// fromSeq detects functions shaped like `iter.Seq[T]` or `iter.Seq2[K, V]`
+// and pulls their elements through an `Iterator`, `iter.Seq2` elements are
+// yielded as `Pair`s
+// a sequence that is not consumed to the end keeps its goroutine until it is
+// closed, which a later `Take` does once it has its elements
+func fromSeq(e interface{}) (Iterator, bool) {
+	if seq, ok := e.(func(func(interface{}) bool)); ok {
+		next, stop := iter.Pull(seq)
+		return &pulled{next: next, stop: stop}, true
+	}
+
+	v := reflect.ValueOf(e)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, false
+	}
+	t := v.Type()
+	if t.NumIn() != 1 || t.NumOut() != 0 {
+		return nil, false
+	}
+	y := t.In(0)
+	if y.Kind() != reflect.Func || y.NumIn() < 1 || y.NumIn() > 2 || y.IsVariadic() ||
+		y.NumOut() != 1 || y.Out(0).Kind() != reflect.Bool {
+		return nil, false
+	}
+
+	seq := func(yield func(interface{}) bool) {
+		v.Call([]reflect.Value{reflect.MakeFunc(y, func(args []reflect.Value) []reflect.Value {
+			var e interface{} = args[0].Interface()
+			if len(args) == 2 {
+				e = Pair{Key: args[0].Interface(), Value: args[1].Interface()}
+			}
+			return []reflect.Value{reflect.ValueOf(yield(e)).Convert(y.Out(0))}
+		})})
+	}
+	next, stop := iter.Pull(seq)
+	return &pulled{next: next, stop: stop}, true
+}
@@ -0,0 +1,8 @@
+//go:build !go1.23
+
+package kundalini
+
+// fromSeq needs range-over-func iterators, which are not available before go1.23
+func fromSeq(e interface{}) (Iterator, bool) {
+	return nil, false
+}
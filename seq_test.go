@@ -0,0 +1,108 @@
+//go:build go1.23
+
+package kundalini_test
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+)
+
+func TestSeq(t *testing.T) {
+	even := func(x interface{}) bool { return x.(int)%2 == 0 }
+	double := func(x interface{}) interface{} { return x.(int) * 2 }
+
+	t.Run("should wrap an iter.Seq", func(t *testing.T) {
+		actual, err := Wrap(slices.Values([]int{0, 1, 2, 3, 4})).
+			Filter(even).
+			Map(double).
+			Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{0, 4, 8}, actual)
+	})
+
+	t.Run("should wrap an iter.Seq2 as pairs", func(t *testing.T) {
+		m := map[string]int{"a": 1, "b": 2, "c": 3}
+		odd := func(x interface{}) bool { return x.(Pair).Value.(int)%2 == 1 }
+		key := func(x interface{}) interface{} { return x.(Pair).Key }
+
+		actual, err := Wrap(maps.All(m)).Filter(odd).Map(key).Release()
+
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []interface{}{"a", "c"}, actual)
+	})
+
+	t.Run("should stop pulling from an infinite iter.Seq", func(t *testing.T) {
+		naturals := func(yield func(int) bool) {
+			for i := 0; ; i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		}
+
+		actual := []interface{}{}
+		for v := range All(Wrap(naturals).Filter(even).Map(double)) {
+			if len(actual) == 3 {
+				break
+			}
+			actual = append(actual, v)
+		}
+
+		assert.Equal(t, []interface{}{0, 4, 8}, actual)
+	})
+
+	t.Run("should drop into standard library iterator helpers", func(t *testing.T) {
+		actual := slices.Collect(All(Wrap([]int{1, 2, 3}).Map(double)))
+
+		assert.Equal(t, []interface{}{2, 4, 6}, actual)
+	})
+
+	t.Run("should range over a chain with its All method", func(t *testing.T) {
+		k := Wrap([]int{1, 2, 3}).Map(double).(*K)
+
+		actual := slices.Collect(k.All())
+
+		assert.Equal(t, []interface{}{2, 4, 6}, actual)
+	})
+
+	t.Run("should stop a sequence once a later take has its elements", func(t *testing.T) {
+		naturals := func(yield func(int) bool) {
+			for i := 0; yield(i); i++ {
+			}
+		}
+		before := runtime.NumGoroutine()
+		for i := 0; i < 50; i++ {
+			actual, err := Wrap(naturals).Take(3).Release()
+			assert.NoError(t, err)
+			assert.Equal(t, []interface{}{0, 1, 2}, actual)
+		}
+
+		assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	})
+
+	t.Run("should close the chain when the loop stops early", func(t *testing.T) {
+		dir := t.TempDir()
+		for range All(Wrap([]int{3, 2, 1}).Sort(byInt, SpillAfter(1), SpillDir(dir))) {
+			break
+		}
+		entries, _ := os.ReadDir(dir)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("should panic on received error", func(t *testing.T) {
+		failure := fmt.Errorf("cursor closed")
+
+		assert.PanicsWithError(t, failure.Error(), func() {
+			for range All(Wrap(&cursor{rows: []string{"a"}, err: failure})) {
+			}
+		})
+	})
+}
@@ -0,0 +1,171 @@
+package slices
+
+import "reflect"
+
+// fast paths for the common unnamed slice types, they follow the same rules
+// as the reflection based `Map`, `Filter` and `Reduce` without boxing through
+// `reflect.Value` in the loop
+
+// fast returns the slice held by `s` for the fast paths to switch on
+// arrays are left to reflection rather than copied out of `s`
+func fast(s reflect.Value) interface{} {
+	if s.Kind() != reflect.Slice || !s.CanInterface() {
+		return nil
+	}
+	return s.Interface()
+}
+
+// mapFast maps `s` without reflection when its type allows it
+func mapFast(s interface{}, fn func(interface{}) interface{}) (interface{}, bool) {
+	switch s := s.(type) {
+	case []interface{}:
+		r := make([]interface{}, len(s))
+		for i, v := range s {
+			if m := fn(v); m != Keep {
+				r[i] = m
+			} else {
+				r[i] = v
+			}
+		}
+		return r, true
+	case []int:
+		r := make([]int, len(s))
+		for i, v := range s {
+			if m := fn(v); m != Keep && m != nil {
+				r[i] = m.(int)
+			} else {
+				r[i] = v
+			}
+		}
+		return r, true
+	case []int64:
+		r := make([]int64, len(s))
+		for i, v := range s {
+			if m := fn(v); m != Keep && m != nil {
+				r[i] = m.(int64)
+			} else {
+				r[i] = v
+			}
+		}
+		return r, true
+	case []float64:
+		r := make([]float64, len(s))
+		for i, v := range s {
+			if m := fn(v); m != Keep && m != nil {
+				r[i] = m.(float64)
+			} else {
+				r[i] = v
+			}
+		}
+		return r, true
+	case []string:
+		r := make([]string, len(s))
+		for i, v := range s {
+			if m := fn(v); m != Keep && m != nil {
+				r[i] = m.(string)
+			} else {
+				r[i] = v
+			}
+		}
+		return r, true
+	case []byte:
+		r := make([]byte, len(s))
+		for i, v := range s {
+			if m := fn(v); m != Keep && m != nil {
+				r[i] = m.(byte)
+			} else {
+				r[i] = v
+			}
+		}
+		return r, true
+	}
+	return nil, false
+}
+
+// filterFast filters `s` without reflection when its type allows it
+func filterFast(s interface{}, p func(interface{}) bool) (interface{}, bool) {
+	switch s := s.(type) {
+	case []interface{}:
+		r := make([]interface{}, 0, len(s))
+		for _, v := range s {
+			if p(v) {
+				r = append(r, v)
+			}
+		}
+		return r, true
+	case []int:
+		r := make([]int, 0, len(s))
+		for _, v := range s {
+			if p(v) {
+				r = append(r, v)
+			}
+		}
+		return r, true
+	case []int64:
+		r := make([]int64, 0, len(s))
+		for _, v := range s {
+			if p(v) {
+				r = append(r, v)
+			}
+		}
+		return r, true
+	case []float64:
+		r := make([]float64, 0, len(s))
+		for _, v := range s {
+			if p(v) {
+				r = append(r, v)
+			}
+		}
+		return r, true
+	case []string:
+		r := make([]string, 0, len(s))
+		for _, v := range s {
+			if p(v) {
+				r = append(r, v)
+			}
+		}
+		return r, true
+	case []byte:
+		r := make([]byte, 0, len(s))
+		for _, v := range s {
+			if p(v) {
+				r = append(r, v)
+			}
+		}
+		return r, true
+	}
+	return nil, false
+}
+
+// reduceFast folds `s` into `acc` without reflection when its type allows it
+func reduceFast(s interface{}, acc interface{}, fn func(interface{}, interface{}) interface{}) (interface{}, bool) {
+	switch s := s.(type) {
+	case []interface{}:
+		for _, v := range s {
+			acc = fn(acc, v)
+		}
+	case []int:
+		for _, v := range s {
+			acc = fn(acc, v)
+		}
+	case []int64:
+		for _, v := range s {
+			acc = fn(acc, v)
+		}
+	case []float64:
+		for _, v := range s {
+			acc = fn(acc, v)
+		}
+	case []string:
+		for _, v := range s {
+			acc = fn(acc, v)
+		}
+	case []byte:
+		for _, v := range s {
+			acc = fn(acc, v)
+		}
+	default:
+		return nil, false
+	}
+	return acc, true
+}
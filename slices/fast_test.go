@@ -0,0 +1,138 @@
+package slices_test
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "gitlab.com/jdbellamy/kundalini"
+	"gitlab.com/jdbellamy/kundalini/slices"
+)
+
+// named slice types are not on the fast paths and so go through reflection
+type ints []int
+type strs []string
+
+func TestMap_FastPathMatchesReflection(t *testing.T) {
+	fn := func(x interface{}) interface{} {
+		switch x.(int) % 3 {
+		case 0:
+			return Keep
+		case 1:
+			return nil
+		}
+		return x.(int) * 10
+	}
+	fast, _ := Wrap([]int{1, 2, 3, 4, 5}).Map(fn).Release()
+	slow, _ := Wrap(ints{1, 2, 3, 4, 5}).Map(fn).Release()
+	assert.Equal(t, []int{1, 20, 3, 4, 50}, fast)
+	assert.Equal(t, ints{1, 20, 3, 4, 50}, slow)
+}
+
+func TestMap_FastPathInterfaceNil(t *testing.T) {
+	fn := func(x interface{}) interface{} {
+		if x == "b" {
+			return nil
+		}
+		return Keep
+	}
+	actual, err := Wrap([]interface{}{"a", "b"}).Map(fn).Release()
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", nil}, actual)
+}
+
+func TestMap_FastPathTypes(t *testing.T) {
+	id := func(x interface{}) interface{} { return x }
+	for _, v := range []interface{}{[]int64{1}, []float64{1.5}, []string{"a"}, []byte("ab"), []interface{}{1, "a"}} {
+		actual, err := Wrap(v).Map(id).Release()
+		assert.NoError(t, err)
+		assert.Equal(t, v, actual)
+	}
+}
+
+func TestFilter_FastPathMatchesReflection(t *testing.T) {
+	short := func(x interface{}) bool { return len(x.(string)) < 3 }
+	fast, _ := Wrap([]string{"a", "abcd", "ab"}).Filter(short).Release()
+	slow, _ := Wrap(strs{"a", "abcd", "ab"}).Filter(short).Release()
+	assert.Equal(t, []string{"a", "ab"}, fast)
+	assert.Equal(t, strs{"a", "ab"}, slow)
+}
+
+func TestReduce_FastPathMatchesReflection(t *testing.T) {
+	sum := func(acc, x interface{}) interface{} { return acc.(int) + x.(int) }
+	fast, _ := Wrap([]int{1, 2, 3}).Reduce(0, sum).Release()
+	slow, _ := Wrap(ints{1, 2, 3}).Reduce(0, sum).Release()
+	assert.Equal(t, []int{6}, fast)
+	assert.Equal(t, fast, slow)
+}
+
+const benchLen = 10000
+
+func benchInts() ([]int, ints) {
+	s := make([]int, benchLen)
+	for i := range s {
+		s[i] = i
+	}
+	return s, ints(s)
+}
+
+func benchStrings() ([]string, strs) {
+	s := make([]string, benchLen)
+	for i := range s {
+		s[i] = strconv.Itoa(i)
+	}
+	return s, strs(s)
+}
+
+func BenchmarkMap(b *testing.B) {
+	fast, slow := benchInts()
+	double := func(x interface{}) interface{} { return x.(int) * 2 }
+	b.Run("fast", func(b *testing.B) {
+		v := reflect.ValueOf(fast)
+		for i := 0; i < b.N; i++ {
+			slices.Map(v, double)
+		}
+	})
+	b.Run("reflect", func(b *testing.B) {
+		v := reflect.ValueOf(slow)
+		for i := 0; i < b.N; i++ {
+			slices.Map(v, double)
+		}
+	})
+}
+
+func BenchmarkFilter(b *testing.B) {
+	fast, slow := benchStrings()
+	short := func(x interface{}) bool { return len(x.(string)) < 4 }
+	b.Run("fast", func(b *testing.B) {
+		v := reflect.ValueOf(fast)
+		for i := 0; i < b.N; i++ {
+			slices.Filter(v, short)
+		}
+	})
+	b.Run("reflect", func(b *testing.B) {
+		v := reflect.ValueOf(slow)
+		for i := 0; i < b.N; i++ {
+			slices.Filter(v, short)
+		}
+	})
+}
+
+func BenchmarkReduce(b *testing.B) {
+	fast, slow := benchInts()
+	sum := func(acc, x interface{}) interface{} { return acc.(int) + x.(int) }
+	b.Run("fast", func(b *testing.B) {
+		v := reflect.ValueOf(fast)
+		for i := 0; i < b.N; i++ {
+			slices.Reduce(v, 0, sum)
+		}
+	})
+	b.Run("reflect", func(b *testing.B) {
+		v := reflect.ValueOf(slow)
+		for i := 0; i < b.N; i++ {
+			slices.Reduce(v, 0, sum)
+		}
+	})
+}
@@ -0,0 +1,331 @@
+package slices
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var NotAStructError = fmt.Errorf("elements must be structs or pointers to structs")
+var UnknownFieldError = fmt.Errorf("no exported field matches the given path")
+var DuplicateFieldError = fmt.Errorf("selected fields must have distinct names")
+var UnorderedFieldError = fmt.Errorf("field type does not support ordering")
+
+// field is a dotted path resolved against a struct type
+type field struct {
+	index [][]int
+	sf    reflect.StructField
+}
+
+// resolve looks up each segment of `path` by field name or `json` tag name
+func resolve(t reflect.Type, path string) (field, error) {
+	f := field{}
+	for _, name := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return f, NotAStructError
+		}
+		sf, ok := lookup(t, name)
+		if !ok {
+			return f, UnknownFieldError
+		}
+		f.index = append(f.index, sf.Index)
+		f.sf = sf
+		t = sf.Type
+	}
+	return f, nil
+}
+
+func lookup(t reflect.Type, name string) (reflect.StructField, bool) {
+	if sf, ok := t.FieldByName(name); ok && sf.PkgPath == "" {
+		return sf, true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if sf.PkgPath == "" && tag == name {
+			return sf, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// get returns the value of `f` in `v`
+// the zero value of the field is returned when a pointer on the path is nil
+func (f field) get(v reflect.Value) reflect.Value {
+	for _, index := range f.index {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Zero(f.sf.Type)
+			}
+			v = v.Elem()
+		}
+		v = v.FieldByIndex(index)
+	}
+	return v
+}
+
+// FieldByPath returns the value of the field at `path` in the struct `v`
+func FieldByPath(v reflect.Value, path string) (reflect.Value, error) {
+	f, err := resolve(v.Type(), path)
+	if err != nil {
+		return v, err
+	}
+	return f.get(v), nil
+}
+
+// elemType returns the type the fields of the elements of `s` are resolved
+// on, the dynamic type of its elements when they are interfaces
+func elemType(s reflect.Value) (reflect.Type, error) {
+	t := s.Type().Elem()
+	if t.Kind() != reflect.Interface {
+		return t, nil
+	}
+	var dyn reflect.Type
+	for i := 0; i < s.Len(); i++ {
+		e := s.Index(i).Elem()
+		switch {
+		case !e.IsValid():
+		case dyn == nil:
+			dyn = e.Type()
+		case e.Type() != dyn:
+			return nil, TypeMismatchError
+		}
+	}
+	if dyn == nil {
+		return t, nil
+	}
+	return dyn, nil
+}
+
+// untyped reports whether `s` holds no elements to resolve fields on
+func untyped(s reflect.Value) bool {
+	return s.Len() == 0 && s.Type().Elem().Kind() == reflect.Interface
+}
+
+func resolveElems(s reflect.Value, path string) (field, error) {
+	t, err := elemType(s)
+	if err != nil {
+		return field{}, err
+	}
+	return resolve(t, path)
+}
+
+func resolveAll(s reflect.Value, paths []string) ([]field, error) {
+	t, err := elemType(s)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]field, len(paths))
+	for i, path := range paths {
+		f, err := resolve(t, path)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = f
+	}
+	return fields, nil
+}
+
+// Pluck maps each element of `s` to the value of the field at `path`
+func Pluck(s reflect.Value, path string) (interface{}, error) {
+	if untyped(s) {
+		return s.Interface(), nil
+	}
+	f, err := resolveElems(s, path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := reflect.MakeSlice(reflect.SliceOf(f.sf.Type), s.Len(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		r.Index(i).Set(f.get(s.Index(i)))
+	}
+
+	return r.Interface(), nil
+}
+
+// Select projects each element of `s` onto a generated struct holding the
+// fields at `paths`, each field keeps the name and tag of the one it copies
+func Select(s reflect.Value, paths []string) (interface{}, error) {
+	if untyped(s) {
+		return s.Interface(), nil
+	}
+	fields, err := resolveAll(s, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	sfs := make([]reflect.StructField, len(fields))
+	names := make(map[string]bool)
+	for i, f := range fields {
+		if names[f.sf.Name] {
+			return nil, DuplicateFieldError
+		}
+		names[f.sf.Name] = true
+		sfs[i] = reflect.StructField{Name: f.sf.Name, Type: f.sf.Type, Tag: f.sf.Tag}
+	}
+
+	rT := reflect.StructOf(sfs)
+	r := reflect.MakeSlice(reflect.SliceOf(rT), s.Len(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		for j, f := range fields {
+			r.Index(i).Field(j).Set(f.get(s.Index(i)))
+		}
+	}
+
+	return r.Interface(), nil
+}
+
+// SelectMap projects each element of `s` onto a map from each of `paths` to
+// the value of its field
+func SelectMap(s reflect.Value, paths []string) (interface{}, error) {
+	if untyped(s) {
+		return s.Interface(), nil
+	}
+	fields, err := resolveAll(s, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]map[string]interface{}, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		m := make(map[string]interface{}, len(fields))
+		for j, f := range fields {
+			m[paths[j]] = f.get(s.Index(i)).Interface()
+		}
+		r[i] = m
+	}
+
+	return r, nil
+}
+
+// WhereField keeps the elements of `s` whose field at `path` equals `value`
+// `value` is converted to the type of the field only when no precision is
+// lost, a field of interface type is compared by its dynamic value
+func WhereField(s reflect.Value, path string, value interface{}) (interface{}, error) {
+	if untyped(s) {
+		return s.Interface(), nil
+	}
+	f, err := resolveElems(s, path)
+	if err != nil {
+		return nil, err
+	}
+
+	want := reflect.ValueOf(value)
+	if f.sf.Type.Kind() != reflect.Interface {
+		ok := true
+		if !want.IsValid() {
+			want = reflect.Zero(f.sf.Type)
+		} else if want, ok = lossless(want, f.sf.Type); !ok {
+			return nil, TypeMismatchError
+		}
+	}
+
+	r := makeSeq(s, 0)
+	for i := 0; i < s.Len(); i++ {
+		v := s.Index(i)
+		if equal(f.get(v), want) {
+			r = reflect.Append(r, v)
+		}
+	}
+
+	return r.Interface(), nil
+}
+
+// lossless converts `v` to `t` when it can be converted back unchanged
+func lossless(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	if v.Type() == t {
+		return v, true
+	}
+	if v.Kind() != t.Kind() && !(numeric(v.Kind()) && numeric(t.Kind())) || !v.Type().ConvertibleTo(t) {
+		return v, false
+	}
+	c := v.Convert(t)
+	if numeric(t.Kind()) && (c.Convert(v.Type()).Interface() != v.Interface() || negative(c) != negative(v)) {
+		return v, false
+	}
+	return c, true
+}
+
+func negative(v reflect.Value) bool {
+	switch k := v.Kind(); {
+	case k >= reflect.Int && k <= reflect.Int64:
+		return v.Int() < 0
+	case k == reflect.Float32 || k == reflect.Float64:
+		return v.Float() < 0
+	}
+	return false
+}
+
+// equal reports whether the field value `got` equals `want`, a field of
+// interface type is compared by its dynamic value
+func equal(got, want reflect.Value) bool {
+	if got.Kind() == reflect.Interface {
+		got = got.Elem()
+		if !got.IsValid() || !want.IsValid() {
+			return got.IsValid() == want.IsValid()
+		}
+		if want, ok := lossless(want, got.Type()); ok {
+			return reflect.DeepEqual(got.Interface(), want.Interface())
+		}
+		return false
+	}
+	return reflect.DeepEqual(got.Interface(), want.Interface())
+}
+
+func numeric(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+// SortByField returns the elements of `s` in ascending order of the field at
+// `path`, elements with equal fields keep their order
+func SortByField(s reflect.Value, path string) (interface{}, error) {
+	if untyped(s) {
+		return s.Interface(), nil
+	}
+	f, err := resolveElems(s, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var less func(a, b reflect.Value) bool
+	switch k := f.sf.Type.Kind(); {
+	case k >= reflect.Int && k <= reflect.Int64:
+		less = func(a, b reflect.Value) bool { return a.Int() < b.Int() }
+	case k >= reflect.Uint && k <= reflect.Uintptr:
+		less = func(a, b reflect.Value) bool { return a.Uint() < b.Uint() }
+	case k == reflect.Float32 || k == reflect.Float64:
+		less = func(a, b reflect.Value) bool { return a.Float() < b.Float() }
+	case k == reflect.String:
+		less = func(a, b reflect.Value) bool { return a.String() < b.String() }
+	default:
+		return nil, UnorderedFieldError
+	}
+
+	r := makeSeq(s, s.Len())
+	reflect.Copy(r, s)
+	keys := make([]reflect.Value, s.Len())
+	for i := range keys {
+		keys[i] = f.get(s.Index(i))
+	}
+	sort.Stable(&byField{keys: keys, less: less, swap: reflect.Swapper(r.Slice(0, r.Len()).Interface())})
+
+	return r.Interface(), nil
+}
+
+type byField struct {
+	keys []reflect.Value
+	less func(a, b reflect.Value) bool
+	swap func(i, j int)
+}
+
+func (b *byField) Len() int           { return len(b.keys) }
+func (b *byField) Less(i, j int) bool { return b.less(b.keys[i], b.keys[j]) }
+func (b *byField) Swap(i, j int) {
+	b.swap(i, j)
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
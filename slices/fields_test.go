@@ -0,0 +1,213 @@
+package slices_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "gitlab.com/jdbellamy/kundalini"
+	"gitlab.com/jdbellamy/kundalini/slices"
+)
+
+type Customer struct {
+	Name    string
+	Status  string `json:"status"`
+	Age     int
+	Address *Address
+	secret  string
+}
+
+type Address struct {
+	City string `json:"city"`
+}
+
+var customers = []Customer{
+	{Name: "Ana", Status: "active", Age: 34, Address: &Address{City: "Wellington"}},
+	{Name: "Bo", Status: "closed", Age: 29},
+	{Name: "Cy", Status: "active", Age: 41, Address: &Address{City: "Perth"}},
+}
+
+func TestPluck_TopLevelField(t *testing.T) {
+	actual, err := Wrap(customers).Pluck("Name").Release()
+	expected := []string{"Ana", "Bo", "Cy"}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestPluck_NestedFieldThroughNilPointer(t *testing.T) {
+	actual, err := Wrap(customers).Pluck("Address.city").Release()
+	expected := []string{"Wellington", "", "Perth"}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestPluck_PointerElements(t *testing.T) {
+	v := []*Customer{&customers[0], &customers[1]}
+	actual, err := Wrap(v).Pluck("Age").Release()
+	expected := []int{34, 29}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestPluck_UnknownFieldError(t *testing.T) {
+	for _, path := range []string{"Email", "secret", "Address.Zip", "Name.Len"} {
+		actual, err := Wrap(customers).Pluck(path).Release()
+		assert.Error(t, err, path)
+		assert.Nil(t, actual)
+	}
+}
+
+func TestPluck_NotAStructError(t *testing.T) {
+	actual, err := Wrap([]int{1}).Pluck("Name").Release()
+	assert.EqualError(t, err, slices.NotAStructError.Error())
+	assert.Nil(t, actual)
+}
+
+func TestSelect_GeneratesStruct(t *testing.T) {
+	actual, err := Wrap(customers).Select("Name", "Address.City").Release()
+	assert.NoError(t, err)
+
+	v := reflect.ValueOf(actual)
+	assert.Equal(t, 3, v.Len())
+	assert.Equal(t, 2, v.Type().Elem().NumField())
+	assert.Equal(t, "Name", v.Type().Elem().Field(0).Name)
+	assert.Equal(t, reflect.StructTag(`json:"city"`), v.Type().Elem().Field(1).Tag)
+	assert.Equal(t, "Cy", v.Index(2).Field(0).String())
+	assert.Equal(t, "Perth", v.Index(2).Field(1).String())
+}
+
+func TestSelect_DuplicateFieldError(t *testing.T) {
+	actual, err := Wrap(customers).Select("Name", "Name").Release()
+	assert.EqualError(t, err, slices.DuplicateFieldError.Error())
+	assert.Nil(t, actual)
+}
+
+func TestSelectMap_KeysArePaths(t *testing.T) {
+	actual, err := Wrap(customers).
+		WhereField("status", "active").
+		SelectMap("Name", "Address.City").
+		Release()
+	expected := []map[string]interface{}{
+		{"Name": "Ana", "Address.City": "Wellington"},
+		{"Name": "Cy", "Address.City": "Perth"},
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestWhereField_ConvertsNumericValue(t *testing.T) {
+	actual, err := Wrap(customers).WhereField("Age", int64(29)).Pluck("Name").Release()
+	expected := []string{"Bo"}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestWhereField_TypeMismatchError(t *testing.T) {
+	actual, err := Wrap(customers).WhereField("Age", "29").Release()
+	assert.EqualError(t, err, slices.TypeMismatchError.Error())
+	assert.Nil(t, actual)
+}
+
+func TestSortByField_IsStable(t *testing.T) {
+	actual, err := Wrap(customers).SortByField("Status").Pluck("Name").Release()
+	expected := []string{"Ana", "Cy", "Bo"}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestSortByField_DoesNotModifyInput(t *testing.T) {
+	actual, err := Wrap(customers).SortByField("Age").Pluck("Age").Release()
+	expected := []int{29, 34, 41}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, "Ana", customers[0].Name)
+}
+
+func TestSortByField_UnorderedFieldError(t *testing.T) {
+	actual, err := Wrap(customers).SortByField("Address").Release()
+	assert.EqualError(t, err, slices.UnorderedFieldError.Error())
+	assert.Nil(t, actual)
+}
+
+func TestWhereField_LossyConversionError(t *testing.T) {
+	for _, value := range []interface{}{34.5, uint64(1) << 63} {
+		actual, err := Wrap(customers).WhereField("Age", value).Release()
+		assert.EqualError(t, err, slices.TypeMismatchError.Error())
+		assert.Nil(t, actual)
+	}
+
+	type Reading struct{ N uint8 }
+	actual, err := Wrap([]Reading{{255}}).WhereField("N", -1).Release()
+	assert.EqualError(t, err, slices.TypeMismatchError.Error())
+	assert.Nil(t, actual)
+}
+
+func TestWhereField_InterfaceFieldByDynamicValue(t *testing.T) {
+	type Setting struct {
+		Name  string
+		Value interface{}
+	}
+	settings := []Setting{{"a", 3}, {"b", "3"}, {"c", 3.5}, {"d", nil}}
+	type Test struct {
+		value    interface{}
+		expected []string
+	}
+	for _, test := range []Test{
+		{3, []string{"a"}},
+		{int64(3), []string{"a"}},
+		{"3", []string{"b"}},
+		{3.5, []string{"c"}},
+		{nil, []string{"d"}},
+	} {
+		actual, err := Wrap(settings).WhereField("Value", test.value).Pluck("Name").Release()
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, actual, test.value)
+	}
+}
+
+func TestFields_AcceptArrays(t *testing.T) {
+	v := [3]Customer{customers[0], customers[1], customers[2]}
+
+	actual, err := Wrap(v).Pluck("Name").Release()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Ana", "Bo", "Cy"}, actual)
+
+	actual, err = Wrap(v).WhereField("status", "active").Pluck("Name").Release()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Ana", "Cy"}, actual)
+
+	actual, err = Wrap(v).SortByField("Age").Pluck("Age").Release()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{29, 34, 41}, actual)
+
+	actual, err = Wrap(v).SelectMap("Name").Release()
+	assert.NoError(t, err)
+	assert.Len(t, actual, 3)
+}
+
+func TestFields_DrainLazyChains(t *testing.T) {
+	actual, err := Wrap(Wrap(customers).Iterator()).WhereField("status", "active").Pluck("Name").Release()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Ana", "Cy"}, actual)
+
+	actual, err = Wrap(Wrap(customers).Iterator()).SortByField("Age").Pluck("Age").Release()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{29, 34, 41}, actual)
+}
+
+func TestFields_InterfaceElementsByDynamicType(t *testing.T) {
+	v := []interface{}{&customers[0], nil, &customers[2]}
+
+	actual, err := Wrap(v).Pluck("Address.city").Release()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Wellington", "", "Perth"}, actual)
+
+	actual, err = Wrap([]interface{}{}).Pluck("Name").Release()
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{}, actual)
+
+	actual, err = Wrap([]interface{}{customers[0], &customers[1]}).Pluck("Name").Release()
+	assert.EqualError(t, err, slices.TypeMismatchError.Error())
+	assert.Nil(t, actual)
+}
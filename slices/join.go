@@ -0,0 +1,147 @@
+package slices
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var UncomparableKeyError = fmt.Errorf("join keys must be comparable")
+var UnsortedInputError = fmt.Errorf("sort-merge join inputs must be sorted by key")
+
+// JoinKind selects which unmatched elements a join keeps
+type JoinKind int
+
+const (
+	InnerJoin JoinKind = iota
+	LeftJoin
+	RightJoin
+	FullJoin
+)
+
+func (kind JoinKind) keepsLeft() bool  { return kind == LeftJoin || kind == FullJoin }
+func (kind JoinKind) keepsRight() bool { return kind == RightJoin || kind == FullJoin }
+
+// HashJoin combines each element of `l` with the elements of `r` that share
+// its key, unmatched elements that `kind` keeps are combined with nil
+// results follow the order of `l`, then of the unmatched elements of `r`,
+// except for a `RightJoin` which follows the order of `r`
+func HashJoin(l, r reflect.Value, kind JoinKind, lKey, rKey func(interface{}) interface{}, combine func(interface{}, interface{}) interface{}) (interface{}, error) {
+	if kind == RightJoin {
+		swapped := func(a, b interface{}) interface{} { return combine(b, a) }
+		return HashJoin(r, l, LeftJoin, rKey, lKey, swapped)
+	}
+
+	index := make(map[interface{}][]int)
+	for j := 0; j < r.Len(); j++ {
+		key := rKey(r.Index(j).Interface())
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			return nil, UncomparableKeyError
+		}
+		index[key] = append(index[key], j)
+	}
+
+	results := make([]interface{}, 0, l.Len())
+	matched := make([]bool, r.Len())
+	for i := 0; i < l.Len(); i++ {
+		lv := l.Index(i).Interface()
+		key := lKey(lv)
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			return nil, UncomparableKeyError
+		}
+		js := index[key]
+		for _, j := range js {
+			matched[j] = true
+			results = append(results, combine(lv, r.Index(j).Interface()))
+		}
+		if len(js) == 0 && kind.keepsLeft() {
+			results = append(results, combine(lv, nil))
+		}
+	}
+	if kind.keepsRight() {
+		for j, ok := range matched {
+			if !ok {
+				results = append(results, combine(nil, r.Index(j).Interface()))
+			}
+		}
+	}
+
+	return typed(results), nil
+}
+
+// MergeJoin joins `l` and `r` like `HashJoin` in a single pass over both,
+// which must already be in ascending order of their keys by `less`
+// results follow the order of the keys
+func MergeJoin(l, r reflect.Value, kind JoinKind, lKey, rKey func(interface{}) interface{}, combine func(interface{}, interface{}) interface{}, less func(a, b interface{}) bool) (interface{}, error) {
+	lKeys, err := sortedKeys(l, lKey, less)
+	if err != nil {
+		return nil, err
+	}
+	rKeys, err := sortedKeys(r, rKey, less)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, 0, l.Len())
+	i, j := 0, 0
+	for i < l.Len() || j < r.Len() {
+		switch {
+		case j == r.Len() || i < l.Len() && less(lKeys[i], rKeys[j]):
+			if kind.keepsLeft() {
+				results = append(results, combine(l.Index(i).Interface(), nil))
+			}
+			i++
+		case i == l.Len() || less(rKeys[j], lKeys[i]):
+			if kind.keepsRight() {
+				results = append(results, combine(nil, r.Index(j).Interface()))
+			}
+			j++
+		default:
+			iEnd, jEnd := run(lKeys, i, less), run(rKeys, j, less)
+			for a := i; a < iEnd; a++ {
+				for b := j; b < jEnd; b++ {
+					results = append(results, combine(l.Index(a).Interface(), r.Index(b).Interface()))
+				}
+			}
+			i, j = iEnd, jEnd
+		}
+	}
+
+	return typed(results), nil
+}
+
+// sortedKeys returns the keys of `s`, which must be in ascending order
+func sortedKeys(s reflect.Value, key func(interface{}) interface{}, less func(a, b interface{}) bool) ([]interface{}, error) {
+	keys := make([]interface{}, s.Len())
+	for i := range keys {
+		keys[i] = key(s.Index(i).Interface())
+		if i > 0 && less(keys[i], keys[i-1]) {
+			return nil, UnsortedInputError
+		}
+	}
+	return keys, nil
+}
+
+// run returns the end of the run of keys equal to `keys[i]`
+func run(keys []interface{}, i int, less func(a, b interface{}) bool) int {
+	j := i + 1
+	for j < len(keys) && !less(keys[i], keys[j]) {
+		j++
+	}
+	return j
+}
+
+// typed returns `results` as a slice of their type when they all share one
+func typed(results []interface{}) interface{} {
+	if len(results) == 0 || results[0] == nil {
+		return results
+	}
+	t := reflect.TypeOf(results[0])
+	r := reflect.MakeSlice(reflect.SliceOf(t), len(results), len(results))
+	for i, v := range results {
+		if v == nil || reflect.TypeOf(v) != t {
+			return results
+		}
+		r.Index(i).Set(reflect.ValueOf(v))
+	}
+	return r.Interface()
+}
@@ -0,0 +1,132 @@
+package slices
+
+import (
+	"container/heap"
+	"math/rand"
+	"reflect"
+	"sort"
+)
+
+type ranked struct {
+	v   interface{}
+	seq int
+}
+
+// best is a min-heap of the greatest elements seen so far, among equal
+// elements the later ones are ranked lower
+type best struct {
+	items []ranked
+	less  func(a, b interface{}) bool
+}
+
+func (b *best) Len() int { return len(b.items) }
+func (b *best) Less(i, j int) bool {
+	x, y := b.items[i], b.items[j]
+	if b.less(x.v, y.v) {
+		return true
+	}
+	return !b.less(y.v, x.v) && x.seq > y.seq
+}
+func (b *best) Swap(i, j int)      { b.items[i], b.items[j] = b.items[j], b.items[i] }
+func (b *best) Push(x interface{}) { b.items = append(b.items, x.(ranked)) }
+func (b *best) Pop() interface{} {
+	x := b.items[len(b.items)-1]
+	b.items = b.items[:len(b.items)-1]
+	return x
+}
+
+// TopKOf pulls every element from `next` and returns the `k` greatest by
+// `less`, greatest first, equal elements keep the order they were pulled in
+// only `k` elements are held at a time
+func TopKOf(next func() (interface{}, bool), k int, less func(a, b interface{}) bool) []interface{} {
+	b := &best{items: make([]ranked, 0, k), less: less}
+	seq := 0
+	for v, ok := next(); ok; v, ok = next() {
+		switch {
+		case b.Len() < k:
+			heap.Push(b, ranked{v: v, seq: seq})
+		case k > 0 && b.less(b.items[0].v, v):
+			b.items[0] = ranked{v: v, seq: seq}
+			heap.Fix(b, 0)
+		}
+		seq++
+	}
+
+	sort.Slice(b.items, func(i, j int) bool { return b.Less(j, i) })
+	r := make([]interface{}, len(b.items))
+	for i, x := range b.items {
+		r[i] = x.v
+	}
+	return r
+}
+
+// SampleOf pulls every element from `next` and returns `n` of them chosen
+// uniformly at random by `rng`, or all of them when there are fewer
+// only `n` elements are held at a time
+func SampleOf(next func() (interface{}, bool), n int, rng *rand.Rand) []interface{} {
+	r := make([]interface{}, 0, n)
+	seen := 0
+	for v, ok := next(); ok; v, ok = next() {
+		seen++
+		if len(r) < n {
+			r = append(r, v)
+			continue
+		}
+		if j := rng.Intn(seen); j < n {
+			r[j] = v
+		}
+	}
+	return r
+}
+
+// TopK returns the `k` greatest elements of `s` by `less` like `TopKOf`
+func TopK(s reflect.Value, k int, less func(a, b interface{}) bool) (interface{}, error) {
+	if k < 0 {
+		return nil, NegativeCountError
+	}
+	return fill(s, TopKOf(pull(s), k, less)), nil
+}
+
+// Sample returns `n` elements of `s` chosen at random like `SampleOf`
+func Sample(s reflect.Value, n int, rng *rand.Rand) (interface{}, error) {
+	if n < 0 {
+		return nil, NegativeCountError
+	}
+	return fill(s, SampleOf(pull(s), n, rng)), nil
+}
+
+// Shuffle returns the elements of `s` in an order chosen at random by `rng`
+func Shuffle(s reflect.Value, rng *rand.Rand) interface{} {
+	r := makeSeq(s, s.Len())
+	reflect.Copy(r, s)
+	if r.Kind() == reflect.Array {
+		swap := reflect.Swapper(r.Slice(0, r.Len()).Interface())
+		rng.Shuffle(r.Len(), swap)
+	} else {
+		rng.Shuffle(r.Len(), reflect.Swapper(r.Interface()))
+	}
+	return r.Interface()
+}
+
+// pull returns a function yielding the elements of `s` in turn
+func pull(s reflect.Value) func() (interface{}, bool) {
+	i := 0
+	return func() (interface{}, bool) {
+		if i >= s.Len() {
+			return nil, false
+		}
+		i++
+		return s.Index(i - 1).Interface(), true
+	}
+}
+
+// fill returns `vs` as a slice shaped like `s`
+func fill(s reflect.Value, vs []interface{}) interface{} {
+	r := makeSeq(s, len(vs))
+	for i, v := range vs {
+		if v != nil {
+			r.Index(i).Set(reflect.ValueOf(v))
+		}
+	}
+	return r.Interface()
+}
@@ -7,53 +7,187 @@ import (
 
 var TypeMismatchError = fmt.Errorf("type mismatch between wrapped value and operand")
 var ExportTargetIsNotPointerError = fmt.Errorf("buf must be a pointer to a slice of the correct type")
+var NegativeCountError = fmt.Errorf("count must not be negative")
+
+type keep struct{}
+
+// Keep is returned by a map function to leave the element unchanged
+var Keep interface{} = keep{}
+
+// makeSeq returns a sequence of `n` zero elements shaped like `s`
+// arrays keep their type when `n` is their length and become slices otherwise
+func makeSeq(s reflect.Value, n int) reflect.Value {
+	if s.Kind() == reflect.Array {
+		if n == s.Len() {
+			return reflect.New(s.Type()).Elem()
+		}
+		return reflect.MakeSlice(reflect.SliceOf(s.Type().Elem()), n, n)
+	}
+	return reflect.MakeSlice(s.Type(), n, n)
+}
+
+func nillable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return true
+	}
+	return false
+}
 
 // Types returns a mapping of the `Type`s of the elements of slice `s`
+// interface elements report their dynamic type, or nil when they hold nil
 func Types(s reflect.Value) []reflect.Type {
 	types := make([]reflect.Type, s.Len())
 	for i := 0; i < s.Len(); i++ {
-		types[i] = s.Index(i).Type()
+		v := s.Index(i)
+		if v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		if v.IsValid() {
+			types[i] = v.Type()
+		}
 	}
 
 	return types
 }
 
 // Map applys `fn` over each element encoiled by `k`
+// elements for which `fn` returns `Keep` are left unchanged, as are elements
+// that cannot hold the nil `fn` returned
 func Map(s reflect.Value, fn func(interface{}) interface{}) interface{} {
-	r := reflect.MakeSlice(s.Type(), s.Len(), s.Len())
+	if r, ok := mapFast(fast(s), fn); ok {
+		return r
+	}
+
+	r := makeSeq(s, s.Len())
+	elemT := s.Type().Elem()
 
 	for i := 0; i < s.Len(); i++ {
 		v := s.Index(i)
-		mapped := fn(v.Interface())
-		if mapped == nil {
-			r.Index(i).Set(v)
-		} else {
-			r.Index(i).Set(reflect.ValueOf(mapped))
-		}
+		set(r.Index(i), v, fn(v.Interface()), elemT)
 	}
 
 	return r.Interface()
 }
 
+// set stores the result of mapping `v` in `dst` following the rules of `Map`
+func set(dst, v reflect.Value, mapped interface{}, elemT reflect.Type) {
+	switch {
+	case mapped == Keep:
+		dst.Set(v)
+	case mapped == nil && nillable(elemT):
+		dst.Set(reflect.Zero(elemT))
+	case mapped == nil:
+		dst.Set(v)
+	default:
+		dst.Set(reflect.ValueOf(mapped))
+	}
+}
+
+// MapE applys `fn` over each element of `s` like `Map`
+// an element `fn` fails on is passed to `reject` and dropped, or stops the
+// map with its error when `reject` is nil
+func MapE(s reflect.Value, fn func(interface{}) (interface{}, error), reject func(interface{}, error)) (interface{}, error) {
+	kept := make([]int, 0, s.Len())
+	results := make([]interface{}, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		v := s.Index(i).Interface()
+		mapped, err := fn(v)
+		if err != nil {
+			if reject == nil {
+				return nil, err
+			}
+			reject(v, err)
+			continue
+		}
+		kept = append(kept, i)
+		results = append(results, mapped)
+	}
+
+	r := makeSeq(s, len(kept))
+	elemT := s.Type().Elem()
+	for i, j := range kept {
+		set(r.Index(i), s.Index(j), results[i], elemT)
+	}
+
+	return r.Interface(), nil
+}
+
 // Filter keeps the elements of `k` that predicate `p` is true for
 func Filter(s reflect.Value, p func(interface{}) bool) interface{} {
 	if s.Len() == 0 {
 		return s.Interface()
 	}
+	if r, ok := filterFast(fast(s), p); ok {
+		return r
+	}
 
-	tmp := make([]interface{}, 0)
+	keep := make([]int, 0)
 	for i := 0; i < s.Len(); i++ {
-		v := s.Index(i).Interface()
-		if p(v) {
-			tmp = append(tmp, v)
+		if p(s.Index(i).Interface()) {
+			keep = append(keep, i)
+		}
+	}
+
+	r := makeSeq(s, len(keep))
+	for i, j := range keep {
+		r.Index(i).Set(s.Index(j))
+	}
+
+	return r.Interface()
+}
+
+// FilterE keeps the elements of `s` that predicate `p` is true for
+// an element `p` fails on is passed to `reject` and dropped, or stops the
+// filter with its error when `reject` is nil
+func FilterE(s reflect.Value, p func(interface{}) (bool, error), reject func(interface{}, error)) (interface{}, error) {
+	var err error
+	r := Filter(s, func(v interface{}) bool {
+		if err != nil {
+			return false
 		}
+		ok, e := p(v)
+		if e != nil {
+			if reject == nil {
+				err = e
+			} else {
+				reject(v, e)
+			}
+			return false
+		}
+		return ok
+	})
+	if err != nil {
+		return nil, err
 	}
+	return r, nil
+}
 
-	r := reflect.MakeSlice(s.Type(), len(tmp), len(tmp))
-	for i, v := range tmp {
-		r.Index(i).Set(reflect.ValueOf(v))
+// FilterTake keeps the first `n` elements of `s` that predicate `p` is true
+// for, `p` is not called again once they have been found
+func FilterTake(s reflect.Value, p func(interface{}) bool, n int) interface{} {
+	keep := make([]int, 0, n)
+	for i := 0; i < s.Len() && len(keep) < n; i++ {
+		if p(s.Index(i).Interface()) {
+			keep = append(keep, i)
+		}
 	}
 
+	r := makeSeq(s, len(keep))
+	for i, j := range keep {
+		r.Index(i).Set(s.Index(j))
+	}
+
+	return r.Interface()
+}
+
+// Take returns the first `n` elements of `s`, or all of them when it is shorter
+func Take(s reflect.Value, n int) interface{} {
+	if n > s.Len() {
+		n = s.Len()
+	}
+	r := makeSeq(s, n)
+	reflect.Copy(r, s)
 	return r.Interface()
 }
 
@@ -63,13 +197,21 @@ func Reduce(s reflect.Value, acc interface{}, fn func(interface{}, interface{})
 		return s.Interface()
 	}
 
-	for i := 0; i < s.Len(); i++ {
-		v := s.Index(i).Interface()
-		acc = fn(acc, v)
+	if r, ok := reduceFast(fast(s), acc, fn); ok {
+		acc = r
+	} else {
+		for i := 0; i < s.Len(); i++ {
+			v := s.Index(i).Interface()
+			acc = fn(acc, v)
+		}
 	}
 
 	var r reflect.Value
 
+	if acc == nil {
+		return []interface{}{nil}
+	}
+
 	accT := reflect.TypeOf(acc)
 	accV := reflect.ValueOf(acc)
 	if accT.Kind() != reflect.Slice {
@@ -88,12 +230,12 @@ func Concat(s reflect.Value, e interface{}) (interface{}, error) {
 	eT := reflect.TypeOf(e)
 	eV := reflect.ValueOf(e)
 
-	if eT != s.Type() {
+	if eT != s.Type() && !sameElem(s, eT) {
 		return nil, TypeMismatchError
 	}
 
 	rLen := s.Len() + eV.Len()
-	r := reflect.MakeSlice(s.Type(), rLen, rLen)
+	r := makeSeq(s, rLen)
 
 	for i := 0; i < s.Len(); i++ {
 		r.Index(i).Set(s.Index(i))
@@ -106,18 +248,46 @@ func Concat(s reflect.Value, e interface{}) (interface{}, error) {
 	return r.Interface(), nil
 }
 
+// sameElem reports whether `t` and `s` are sequences of the same elements
+// where at least one of them is an array
+func sameElem(s reflect.Value, t reflect.Type) bool {
+	if t == nil || s.Kind() != reflect.Array && t.Kind() != reflect.Array {
+		return false
+	}
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem() == s.Type().Elem()
+}
+
 // Export attempts to copy the current elements of `k` to the provided target
 // a new slice with len and cap based on `k`s elements is generated at `ptr`
+// an array at `ptr` is filled in place with as many elements as it can hold
 func Export(s reflect.Value, ptr reflect.Value) interface{} {
 	if ptr.Type().Kind() != reflect.Ptr {
 		panic(ExportTargetIsNotPointerError)
 	}
 
+	if ptr.Elem().Kind() == reflect.Array {
+		reflect.Copy(ptr.Elem(), s)
+		return s.Interface()
+	}
+
 	kLen := s.Len()
 	slice := reflect.MakeSlice(ptr.Type().Elem(), kLen, kLen)
 	ptr.Elem().Set(slice)
 	ptr.Elem().SetLen(kLen)
-	reflect.Copy(ptr.Elem(), s)
+	if slice.Type().Elem() == s.Type().Elem() {
+		reflect.Copy(ptr.Elem(), s)
+		return s.Interface()
+	}
+
+	for i := 0; i < kLen; i++ {
+		v := s.Index(i)
+		if v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		if v.IsValid() {
+			slice.Index(i).Set(v)
+		}
+	}
 
 	return s.Interface()
 }
@@ -278,3 +278,163 @@ func TestExport_ErrorsOnTypeMismatch(t *testing.T) {
 	}
 	assert.Panics(t, panics)
 }
+
+func TestTypes_MixedDynamicTypesAndNil(t *testing.T) {
+	v := []interface{}{1, "a", nil, 2.5}
+	actual, err := Wrap(v).Types().Release()
+	expected := []reflect.Type{reflect.TypeOf(0), reflect.TypeOf(""), nil, reflect.TypeOf(0.0)}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestFilter_InterfaceSliceWithNils(t *testing.T) {
+	v := []interface{}{nil, 1, nil, "a"}
+	isNil := func(x interface{}) bool {
+		return x == nil
+	}
+	actual, err := Wrap(v).Filter(isNil).Release()
+	expected := []interface{}{nil, nil}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestFilter_PointerSliceWithNils(t *testing.T) {
+	one := 1
+	v := []*int{nil, &one, nil}
+	all := func(x interface{}) bool {
+		return true
+	}
+	actual, err := Wrap(v).Filter(all).Release()
+	assert.NoError(t, err)
+	assert.Equal(t, v, actual)
+}
+
+func TestMap_FnReturnsNilForInterfaceSlice(t *testing.T) {
+	v := []interface{}{1, "a", 2}
+	dropStrings := func(x interface{}) interface{} {
+		if _, ok := x.(string); ok {
+			return nil
+		}
+		return x.(int) * 2
+	}
+	actual, err := Wrap(v).Map(dropStrings).Release()
+	expected := []interface{}{2, nil, 4}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMap_FnReturnsKeep(t *testing.T) {
+	v := []interface{}{nil, "a", 2}
+	keepNonInts := func(x interface{}) interface{} {
+		if n, ok := x.(int); ok {
+			return n + 1
+		}
+		return Keep
+	}
+	actual, err := Wrap(v).Map(keepNonInts).Release()
+	expected := []interface{}{nil, "a", 3}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMap_FnReturnsNilForPointerSlice(t *testing.T) {
+	one := 1
+	v := []*int{&one, &one}
+	toNil := func(x interface{}) interface{} {
+		return nil
+	}
+	actual, err := Wrap(v).Map(toNil).Release()
+	expected := []*int{nil, nil}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestReduce_InterfaceSliceWithNils(t *testing.T) {
+	v := []interface{}{nil, 1, "a", nil}
+	countNils := func(acc interface{}, x interface{}) interface{} {
+		if x == nil {
+			return acc.(int) + 1
+		}
+		return acc
+	}
+	actual, err := Wrap(v).Reduce(0, countNils).Release()
+	expected := []int{2}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestReduce_AccIsNil(t *testing.T) {
+	v := []interface{}{1, 2}
+	last := func(acc interface{}, x interface{}) interface{} {
+		return nil
+	}
+	actual, err := Wrap(v).Reduce(0, last).Release()
+	expected := []interface{}{nil}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMap_ArrayReleasesAsArray(t *testing.T) {
+	v := [3]int{1, 2, 3}
+	double := func(x interface{}) interface{} {
+		return x.(int) * 2
+	}
+	actual, err := Wrap(v).Map(double).Release()
+	expected := [3]int{2, 4, 6}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, [3]int{1, 2, 3}, v)
+}
+
+func TestFilter_ArrayKeepsLengthReleasesAsArray(t *testing.T) {
+	v := [16]byte{}
+	all := func(x interface{}) bool {
+		return true
+	}
+	actual, err := Wrap(v).Filter(all).Release()
+	assert.NoError(t, err)
+	assert.Equal(t, v, actual)
+}
+
+func TestFilter_ArrayChangesLengthReleasesAsSlice(t *testing.T) {
+	v := [4]int{1, 2, 3, 4}
+	even := func(x interface{}) bool {
+		return x.(int)%2 == 0
+	}
+	actual, err := Wrap(v).Filter(even).Release()
+	expected := []int{2, 4}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestConcat_ArrayWithSliceReleasesAsSlice(t *testing.T) {
+	v := [2]string{"a", "b"}
+	actual, err := Wrap(v).
+		Concat([]string{"c"}).
+		Concat([1]string{"d"}).
+		Release()
+	expected := []string{"a", "b", "c", "d"}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestConcat_ArrayTypeMismatchError(t *testing.T) {
+	actual, err := Wrap([2]string{}).Concat([]int{1}).Release()
+	assert.EqualError(t, err, slices.TypeMismatchError.Error())
+	assert.Nil(t, actual)
+}
+
+func TestTypes_Array(t *testing.T) {
+	actual, err := Wrap([2]int{}).Types().Release()
+	expected := []reflect.Type{reflect.TypeOf(0), reflect.TypeOf(0)}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestExport_ArrayToArrayPointer(t *testing.T) {
+	v := []int{1, 2, 3}
+	actual := [2]int{}
+	Wrap(v).Export(reflect.ValueOf(&actual)).ReleaseOrPanic()
+	expected := [2]int{1, 2}
+	assert.Equal(t, expected, actual)
+}
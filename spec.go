@@ -0,0 +1,251 @@
+package kundalini
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+var InvalidSpecError = fmt.Errorf("spec must be a document with a list of stages")
+var UnknownStageError = fmt.Errorf("unknown stage")
+var InvalidStageArgumentError = fmt.Errorf("invalid stage argument")
+
+// SpecError describes why a stage of a pipeline spec could not be loaded
+type SpecError struct {
+	Stage int
+	Op    string
+	Name  string
+	Err   error
+}
+
+func (e *SpecError) Error() string {
+	msg := fmt.Sprintf("stage %d", e.Stage)
+	if e.Op != "" {
+		msg += fmt.Sprintf(" (%s)", e.Op)
+	}
+	msg += fmt.Sprintf(": %v", e.Err)
+	if e.Name != "" {
+		msg += fmt.Sprintf(" %q", e.Name)
+	}
+	return msg
+}
+
+// Unwrap returns the error of the stage so that `errors.Is` matches it
+func (e *SpecError) Unwrap() error { return e.Err }
+
+// Pipeline is a chain of stages loaded from a declarative spec
+type Pipeline struct {
+	stages []stage
+}
+
+type stage struct {
+	op   string
+	name string
+	fn   Fn
+	fns  []Fn
+	p    Predicate
+	t    Transform
+	init interface{}
+	n    int
+}
+
+// Apply runs each stage of `p` against `k` in order
+func (p *Pipeline) Apply(k Kundalini) Kundalini {
+	for _, s := range p.stages {
+		switch s.op {
+		case "filter":
+			k = k.Filter(s.p)
+		case "map":
+			if s.fns != nil {
+				k = mapFused(k, s.fns)
+				continue
+			}
+			k = k.Map(s.fn)
+		case "reduce":
+			k = k.Reduce(s.init, s.t)
+		case "push":
+			k = k.Push()
+		case "pop":
+			k = k.Pop()
+		case "types":
+			k = k.Types()
+		case "take":
+			k = k.Take(s.n)
+		case "filter+take":
+			k = filterTake(k, s.p, s.n)
+		}
+	}
+	return k
+}
+
+// LoadJSON builds a `Pipeline` from a JSON spec, resolving names with `reg`
+//
+//	{"stages": [{"filter": "even"}, {"map": "double"},
+//	            {"reduce": {"init": 3, "fn": "sum"}}, "push", "pop",
+//	            {"take": 2}]}
+//
+// integral numbers are decoded as `int` and all others as `float64`
+func LoadJSON(r io.Reader, reg *Registry) (*Pipeline, error) {
+	var doc interface{}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return load(normalize(doc), reg)
+}
+
+// LoadYAML builds a `Pipeline` from a YAML spec, resolving names with `reg`
+//
+//	stages:
+//	  - filter: even
+//	  - map: double
+//	  - reduce: {init: 3, fn: sum}
+//	  - push
+//	  - pop
+//	  - take: 2
+func LoadYAML(r io.Reader, reg *Registry) (*Pipeline, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return load(normalize(doc), reg)
+}
+
+func load(doc interface{}, reg *Registry) (*Pipeline, error) {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, InvalidSpecError
+	}
+	list, ok := m["stages"].([]interface{})
+	if !ok {
+		return nil, InvalidSpecError
+	}
+
+	p := &Pipeline{stages: make([]stage, 0, len(list))}
+	for i, raw := range list {
+		s, err := loadStage(i, raw, reg)
+		if err != nil {
+			return nil, err
+		}
+		p.stages = append(p.stages, s)
+	}
+	return p, nil
+}
+
+func loadStage(i int, raw interface{}, reg *Registry) (stage, error) {
+	var op string
+	var arg interface{}
+
+	switch v := raw.(type) {
+	case string:
+		op = v
+	case map[string]interface{}:
+		if len(v) != 1 {
+			return stage{}, &SpecError{Stage: i, Err: InvalidStageArgumentError}
+		}
+		for k, a := range v {
+			op, arg = k, a
+		}
+	default:
+		return stage{}, &SpecError{Stage: i, Err: InvalidStageArgumentError}
+	}
+
+	s := stage{op: op}
+	fail := func(name string, err error) (stage, error) {
+		return stage{}, &SpecError{Stage: i, Op: op, Name: name, Err: err}
+	}
+
+	switch op {
+	case "filter":
+		name, ok := arg.(string)
+		if !ok {
+			return fail("", InvalidStageArgumentError)
+		}
+		p, err := reg.Predicate(name)
+		if err != nil {
+			return fail(name, err)
+		}
+		s.name, s.p = name, p
+	case "map":
+		name, ok := arg.(string)
+		if !ok {
+			return fail("", InvalidStageArgumentError)
+		}
+		fn, err := reg.Fn(name)
+		if err != nil {
+			return fail(name, err)
+		}
+		s.name, s.fn = name, fn
+	case "reduce":
+		args, ok := arg.(map[string]interface{})
+		if !ok || len(args) != 2 {
+			return fail("", InvalidStageArgumentError)
+		}
+		init, ok := args["init"]
+		if !ok || init == nil {
+			return fail("", InvalidStageArgumentError)
+		}
+		name, ok := args["fn"].(string)
+		if !ok {
+			return fail("", InvalidStageArgumentError)
+		}
+		t, err := reg.Transform(name)
+		if err != nil {
+			return fail(name, err)
+		}
+		s.name, s.t, s.init = name, t, init
+	case "take":
+		n, ok := arg.(int)
+		if !ok || n < 0 {
+			return fail("", InvalidStageArgumentError)
+		}
+		s.n = n
+	case "push", "pop", "types":
+		if arg != nil {
+			return fail("", InvalidStageArgumentError)
+		}
+	default:
+		return fail("", UnknownStageError)
+	}
+	return s, nil
+}
+
+// normalize converts decoded JSON and YAML documents to a common shape
+// maps are keyed by string and numbers are `int` when integral
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[k] = normalize(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(t))
+		for i, e := range t {
+			l[i] = normalize(e)
+		}
+		return l
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return int(n)
+		}
+		f, _ := t.Float64()
+		return f
+	}
+	return v
+}
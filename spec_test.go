@@ -0,0 +1,124 @@
+package kundalini_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+)
+
+func testRegistry() *Registry {
+	return NewRegistry().
+		RegisterPredicate("even", func(x interface{}) bool {
+			return x.(int)%2 == 0
+		}).
+		RegisterFn("double", func(x interface{}) interface{} {
+			return x.(int) * 2
+		}).
+		RegisterTransform("sum", func(acc interface{}, x interface{}) interface{} {
+			return acc.(int) + x.(int)
+		})
+}
+
+func TestLoad(t *testing.T) {
+
+	t.Run("should build the same chain from JSON and YAML", func(t *testing.T) {
+		jsonSpec := `{"stages": [
+			{"filter": "even"},
+			{"map": "double"},
+			"push",
+			{"reduce": {"init": 3, "fn": "sum"}},
+			"types",
+			"pop"
+		]}`
+
+		yamlSpec := `
+stages:
+  - filter: even
+  - map: double
+  - push
+  - reduce: {init: 3, fn: sum}
+  - types
+  - pop
+`
+		fromJSON, err := LoadJSON(strings.NewReader(jsonSpec), testRegistry())
+		assert.NoError(t, err)
+		fromYAML, err := LoadYAML(strings.NewReader(yamlSpec), testRegistry())
+		assert.NoError(t, err)
+
+		for _, p := range []*Pipeline{fromJSON, fromYAML} {
+			actual, err := p.Apply(Wrap([]int{0, 1, 2, 3, 4})).Release()
+
+			assert.NoError(t, err)
+			assert.Equal(t, []int{0, 4, 8}, actual)
+		}
+	})
+
+	t.Run("should apply reduce with the decoded initial value", func(t *testing.T) {
+		p, err := LoadJSON(strings.NewReader(`{"stages": [{"reduce": {"init": 3, "fn": "sum"}}]}`), testRegistry())
+		assert.NoError(t, err)
+
+		actual, err := p.Apply(Wrap([]int{1, 2})).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{6}, actual)
+	})
+
+	t.Run("should fail on unknown names and bad arguments", func(t *testing.T) {
+		type Test struct {
+			spec string
+			err  error
+			msg  string
+		}
+
+		tests := []Test{{
+			spec: "stages:\n  - map: triple\n",
+			err:  UnknownFnError,
+			msg:  `stage 0 (map): unknown Fn "triple"`,
+		}, {
+			spec: "stages:\n  - map: double\n  - filter: odd\n",
+			err:  UnknownPredicateError,
+			msg:  `stage 1 (filter): unknown Predicate "odd"`,
+		}, {
+			spec: "stages:\n  - reduce: {init: 0, fn: product}\n",
+			err:  UnknownTransformError,
+			msg:  `stage 0 (reduce): unknown Transform "product"`,
+		}, {
+			spec: "stages:\n  - reduce: {fn: sum}\n",
+			err:  InvalidStageArgumentError,
+			msg:  "stage 0 (reduce): invalid stage argument",
+		}, {
+			spec: "stages:\n  - map: [double]\n",
+			err:  InvalidStageArgumentError,
+			msg:  "stage 0 (map): invalid stage argument",
+		}, {
+			spec: "stages:\n  - push: 1\n",
+			err:  InvalidStageArgumentError,
+			msg:  "stage 0 (push): invalid stage argument",
+		}, {
+			spec: "stages:\n  - sort\n",
+			err:  UnknownStageError,
+			msg:  "stage 0 (sort): unknown stage",
+		}}
+
+		for _, tt := range tests {
+			p, err := LoadYAML(strings.NewReader(tt.spec), testRegistry())
+
+			assert.Nil(t, p)
+			assert.EqualError(t, err, tt.msg)
+			assert.IsType(t, &SpecError{}, err)
+			assert.True(t, errors.Is(err, tt.err), tt.msg)
+		}
+	})
+
+	t.Run("should reject documents without a list of stages", func(t *testing.T) {
+		for _, spec := range []string{`[]`, `{"stages": "map"}`, `{}`} {
+			p, err := LoadJSON(strings.NewReader(spec), testRegistry())
+
+			assert.Nil(t, p)
+			assert.EqualError(t, err, InvalidSpecError.Error())
+		}
+	})
+}
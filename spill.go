@@ -0,0 +1,94 @@
+package kundalini
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/iterators"
+	"gitlab.com/jdbellamy/kundalini/spill"
+)
+
+// Group is the elements of a chain sharing a key
+type Group = spill.Group
+
+// Codec encodes the runs `Sort` and `GroupBy` spill to disk
+type Codec = spill.Codec
+
+// SpillOption configures how `Sort` and `GroupBy` spill to disk
+type SpillOption func(*spill.Config)
+
+// SpillAfter holds at most `n` elements in memory, sorting and spilling
+// them to disk as a run whenever that many are collected
+func SpillAfter(n int) SpillOption {
+	return func(c *spill.Config) {
+		c.RunSize = n
+	}
+}
+
+// SpillDir writes runs to `dir` instead of the system temp directory
+func SpillDir(dir string) SpillOption {
+	return func(c *spill.Config) {
+		c.Dir = dir
+	}
+}
+
+// SpillCodec encodes runs with `codec` instead of `spill.Gob`
+func SpillCodec(codec Codec) SpillOption {
+	return func(c *spill.Config) {
+		c.Codec = codec
+	}
+}
+
+// SpillContext stops a sort and removes its runs when `ctx` is done
+func SpillContext(ctx context.Context) SpillOption {
+	return func(c *spill.Config) {
+		c.Context = ctx
+	}
+}
+
+func newSpillConfig(opts []SpillOption) spill.Config {
+	c := spill.Config{RunSize: 1 << 20, Codec: spill.Gob, Context: context.Background()}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// Sort puts the elements of `k` in ascending order by `less`, equal elements
+// keep their order
+// elements beyond the memory limit of `SpillAfter` are spilled to disk in
+// sorted runs that are merged back as the lazy result is pulled, they must
+// share one concrete type the codec can encode
+// nothing is sorted until the first element is pulled, and runs are removed
+// once the result is exhausted or fails, a later `Take` has its elements, a
+// partly read `Iterator` is closed, or the context of `SpillContext` is done
+// an `Iterator` that is left partly read without being closed keeps its runs
+// and their open files until that context is done, which for the default
+// context is never
+func (k *K) Sort(less Less, opts ...SpillOption) Kundalini {
+	if k.err != nil {
+		return k
+	}
+	it := spill.Sort(k.Iterator(), less, newSpillConfig(opts))
+	if err := iterators.Err(it); err != nil {
+		return k.fail("Sort", err)
+	}
+	logrus.Debug("  sort: ", it)
+	return k.next("Sort", less, it)
+}
+
+// GroupBy replaces the elements of `k` with a `Group` for each of the keys
+// `key` returns, in ascending order of the keys by `less`
+// elements are sorted by key like `Sort` and spill to disk the same way,
+// only the group being pulled is held in memory beyond the memory limit
+func (k *K) GroupBy(key Fn, less Less, opts ...SpillOption) Kundalini {
+	if k.err != nil {
+		return k
+	}
+	it := spill.GroupBy(k.Iterator(), key, less, newSpillConfig(opts))
+	if err := iterators.Err(it); err != nil {
+		return k.fail("GroupBy", err)
+	}
+	logrus.Debug(" group: ", it)
+	return k.next("GroupBy", key, it)
+}
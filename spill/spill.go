@@ -0,0 +1,404 @@
+package spill
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+
+	"gitlab.com/jdbellamy/kundalini/iterators"
+)
+
+var MixedTypesError = fmt.Errorf("spilled elements must share one concrete type")
+var InvalidRunSizeError = fmt.Errorf("run size must be at least 1")
+
+// Encoder writes elements to a run
+type Encoder interface {
+	Encode(v interface{}) error
+}
+
+// Decoder reads the elements of a run back into the value `v` points to
+type Decoder interface {
+	Decode(v interface{}) error
+}
+
+// Codec encodes the elements of a run on disk
+type Codec interface {
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+type gobCodec struct{}
+
+func (gobCodec) NewEncoder(w io.Writer) Encoder { return gob.NewEncoder(w) }
+func (gobCodec) NewDecoder(r io.Reader) Decoder { return gob.NewDecoder(r) }
+
+// Gob encodes runs with `encoding/gob`, only exported fields are kept
+var Gob Codec = gobCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) NewEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
+func (jsonCodec) NewDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+
+// JSON encodes runs with `encoding/json`, one element a line
+var JSON Codec = jsonCodec{}
+
+// Config bounds the memory of a sort and says where its runs are spilled
+type Config struct {
+	// RunSize is how many elements are sorted in memory before they are
+	// spilled to disk as a run
+	RunSize int
+	// Dir holds the run files, the system temp directory when empty
+	Dir string
+	// Codec encodes the runs, `Gob` when nil
+	Codec Codec
+	// Context cancels the sort, its runs are removed once it is done
+	Context context.Context
+}
+
+// runs are the files a sort spilled, removed together
+type runs struct {
+	mu    sync.Mutex
+	files []*os.File
+}
+
+// remove closes and deletes every run, it is safe to call more than once
+func (rs *runs) remove() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for _, f := range rs.files {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	rs.files = nil
+}
+
+// spill sorts `buf` and writes it to a new run
+func (rs *runs) spill(buf []interface{}, less func(a, b interface{}) bool, cfg Config) error {
+	sort.SliceStable(buf, func(i, j int) bool { return less(buf[i], buf[j]) })
+	f, err := os.CreateTemp(cfg.Dir, "kundalini-*.run")
+	if err != nil {
+		return err
+	}
+	rs.mu.Lock()
+	rs.files = append(rs.files, f)
+	rs.mu.Unlock()
+
+	w := bufio.NewWriter(f)
+	enc := cfg.Codec.NewEncoder(w)
+	for _, v := range buf {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	return err
+}
+
+type sortIter struct {
+	mu   sync.Mutex
+	src  iterators.Iterator
+	less func(a, b interface{}) bool
+	cfg  Config
+	out  iterators.Iterator
+}
+
+func (it *sortIter) Next() (interface{}, bool) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if it.out == nil {
+		it.out = sortRuns(it.src, it.less, it.cfg)
+	}
+	return it.out.Next()
+}
+
+func (it *sortIter) Err() error {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if it.out == nil {
+		return nil
+	}
+	return iterators.Err(it.out)
+}
+
+// ElemType is that of the source, elements are yielded as they were pulled
+func (it *sortIter) ElemType() reflect.Type { return iterators.ElemType(it.src) }
+
+// Close removes the runs, a sort that has not started never will
+func (it *sortIter) Close() {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if it.out == nil {
+		it.out = iterators.Failed(nil)
+		iterators.Close(it.src)
+		return
+	}
+	iterators.Close(it.out)
+}
+
+// Sort lazily yields the elements of `it` in ascending order by `less`,
+// equal elements keep their order
+// nothing is pulled from `it` until the first element is, then at most
+// `RunSize` elements are held in memory, when `it` has more they are sorted
+// in runs that are spilled to disk and merged back as they are pulled
+// the runs are removed once the merge is exhausted, fails or is closed with
+// `iterators.Close`, or when the context is cancelled, a merge that is left
+// partly read without being closed keeps its runs on disk until then
+// spilled elements must share one concrete type
+func Sort(it iterators.Iterator, less func(a, b interface{}) bool, cfg Config) iterators.Iterator {
+	if cfg.RunSize < 1 {
+		return iterators.Failed(InvalidRunSizeError)
+	}
+	if cfg.Codec == nil {
+		cfg.Codec = Gob
+	}
+	if cfg.Context == nil {
+		cfg.Context = context.Background()
+	}
+	return &sortIter{src: it, less: less, cfg: cfg}
+}
+
+// sortRuns pulls every element of `it`, spilling the runs that do not fit in
+// memory, and returns an iterator over them in order
+func sortRuns(it iterators.Iterator, less func(a, b interface{}) bool, cfg Config) iterators.Iterator {
+	rs := &runs{}
+	fail := func(err error) iterators.Iterator {
+		rs.remove()
+		return iterators.Failed(err)
+	}
+	var t reflect.Type
+	mixed := false
+	buf := make([]interface{}, 0)
+	for v, ok := it.Next(); ok; v, ok = it.Next() {
+		if err := cfg.Context.Err(); err != nil {
+			return fail(err)
+		}
+		if len(buf) == 0 && len(rs.files) == 0 {
+			t = reflect.TypeOf(v)
+		}
+		mixed = mixed || t == nil || reflect.TypeOf(v) != t
+		buf = append(buf, v)
+		if len(buf) == cfg.RunSize {
+			if mixed {
+				// runs are decoded into a single type
+				return fail(MixedTypesError)
+			}
+			if err := rs.spill(buf, less, cfg); err != nil {
+				return fail(err)
+			}
+			buf = buf[:0]
+		}
+	}
+	if err := iterators.Err(it); err != nil {
+		return fail(err)
+	}
+
+	if len(rs.files) == 0 {
+		sort.SliceStable(buf, func(i, j int) bool { return less(buf[i], buf[j]) })
+		return iterators.FromSlice(reflect.ValueOf(buf))
+	}
+	if mixed {
+		return fail(MixedTypesError)
+	}
+	if len(buf) > 0 {
+		if err := rs.spill(buf, less, cfg); err != nil {
+			return fail(err)
+		}
+	}
+	m, err := merge(rs, t, less, cfg)
+	if err != nil {
+		return fail(err)
+	}
+	return m
+}
+
+type head struct {
+	v   interface{}
+	run int
+}
+
+// heads is a min-heap of the next element of each run, ties go to the
+// earlier run so the merge is stable
+type heads struct {
+	items []head
+	less  func(a, b interface{}) bool
+}
+
+func (h *heads) Len() int { return len(h.items) }
+func (h *heads) Less(i, j int) bool {
+	x, y := h.items[i], h.items[j]
+	if h.less(x.v, y.v) {
+		return true
+	}
+	return !h.less(y.v, x.v) && x.run < y.run
+}
+func (h *heads) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *heads) Push(x interface{}) { h.items = append(h.items, x.(head)) }
+func (h *heads) Pop() interface{} {
+	x := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return x
+}
+
+type mergeIter struct {
+	mu   sync.Mutex
+	rs   *runs
+	t    reflect.Type
+	decs []Decoder
+	h    *heads
+	ctx  context.Context
+	stop chan struct{}
+	err  error
+}
+
+// merge reads the runs of `rs` back in order with a heap of their heads
+func merge(rs *runs, t reflect.Type, less func(a, b interface{}) bool, cfg Config) (*mergeIter, error) {
+	m := &mergeIter{rs: rs, t: t, h: &heads{less: less}, ctx: cfg.Context, stop: make(chan struct{})}
+	for i, f := range rs.files {
+		m.decs = append(m.decs, cfg.Codec.NewDecoder(bufio.NewReader(f)))
+		if err := m.pull(i); err != nil {
+			return nil, err
+		}
+	}
+	if done := cfg.Context.Done(); done != nil {
+		go func() {
+			select {
+			case <-done:
+				rs.remove()
+			case <-m.stop:
+			}
+		}()
+	}
+	return m, nil
+}
+
+// pull pushes the next element of run `i` onto the heap, if it has one
+func (m *mergeIter) pull(i int) error {
+	p := reflect.New(m.t)
+	err := m.decs[i].Decode(p.Interface())
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	heap.Push(m.h, head{v: p.Elem().Interface(), run: i})
+	return nil
+}
+
+func (m *mergeIter) Next() (interface{}, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil || m.h.Len() == 0 {
+		return nil, false
+	}
+	if err := m.ctx.Err(); err != nil {
+		m.finish(err)
+		return nil, false
+	}
+	x := heap.Pop(m.h).(head)
+	if err := m.pull(x.run); err != nil {
+		if m.ctx.Err() != nil {
+			// the runs were removed under the decoder
+			err = m.ctx.Err()
+		}
+		m.finish(err)
+		return nil, false
+	}
+	if m.h.Len() == 0 {
+		m.finish(nil)
+	}
+	return x.v, true
+}
+
+func (m *mergeIter) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+// Close stops the merge early and removes the runs
+func (m *mergeIter) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.finish(m.err)
+}
+
+// finish removes the runs and records why the merge stopped
+func (m *mergeIter) finish(err error) {
+	m.err = err
+	m.h.items = nil
+	m.rs.remove()
+	select {
+	case <-m.stop:
+	default:
+		close(m.stop)
+	}
+}
+
+// Group is the elements sharing a key
+type Group struct {
+	Key      interface{}
+	Elements []interface{}
+}
+
+type groupIter struct {
+	src  iterators.Iterator
+	key  func(interface{}) interface{}
+	less func(a, b interface{}) bool
+	next *Group
+}
+
+func (it *groupIter) Next() (interface{}, bool) {
+	for {
+		v, ok := it.src.Next()
+		if !ok {
+			g := it.next
+			it.next = nil
+			if g == nil || iterators.Err(it.src) != nil {
+				return nil, false
+			}
+			return *g, true
+		}
+		k := it.key(v)
+		if it.next != nil && !it.less(it.next.Key, k) {
+			it.next.Elements = append(it.next.Elements, v)
+			continue
+		}
+		g := it.next
+		it.next = &Group{Key: k, Elements: []interface{}{v}}
+		if g != nil {
+			return *g, true
+		}
+	}
+}
+
+func (it *groupIter) Err() error { return iterators.Err(it.src) }
+
+func (it *groupIter) ElemType() reflect.Type { return reflect.TypeOf(Group{}) }
+
+func (it *groupIter) Close() {
+	iterators.Close(it.src)
+	it.next = nil
+}
+
+// GroupBy lazily yields a `Group` for each key of the elements of `it`, in
+// ascending order of the keys by `less`
+// the elements are sorted by key like `Sort`, so only the elements of the
+// group being yielded are held in memory beyond a run, and its runs are
+// removed the same way
+func GroupBy(it iterators.Iterator, key func(interface{}) interface{}, less func(a, b interface{}) bool, cfg Config) iterators.Iterator {
+	byKey := func(a, b interface{}) bool { return less(key(a), key(b)) }
+	return &groupIter{src: Sort(it, byKey, cfg), key: key, less: less}
+}
@@ -0,0 +1,139 @@
+package spill_test
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.com/jdbellamy/kundalini/iterators"
+	"gitlab.com/jdbellamy/kundalini/spill"
+)
+
+type Reading struct {
+	Sensor string
+	Value  int
+}
+
+func byValue(a, b interface{}) bool { return a.(Reading).Value < b.(Reading).Value }
+
+func readings(values ...int) iterators.Iterator {
+	rs := make([]Reading, len(values))
+	for i, v := range values {
+		rs[i] = Reading{Sensor: string(rune('a' + i)), Value: v}
+	}
+	return iterators.FromSlice(reflect.ValueOf(rs))
+}
+
+func values(t *testing.T, it iterators.Iterator) []int {
+	vs, err := iterators.Drain(it)
+	assert.NoError(t, err)
+	r := make([]int, len(vs))
+	for i, v := range vs {
+		r[i] = v.(Reading).Value
+	}
+	return r
+}
+
+func runFiles(t *testing.T, dir string) int {
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	return len(entries)
+}
+
+func TestSort_InMemoryDoesNotSpill(t *testing.T) {
+	dir := t.TempDir()
+	it := spill.Sort(readings(3, 1, 2), byValue, spill.Config{RunSize: 10, Dir: dir})
+	assert.Equal(t, 0, runFiles(t, dir))
+	assert.Equal(t, []int{1, 2, 3}, values(t, it))
+}
+
+func TestSort_MergesSpilledRuns(t *testing.T) {
+	for _, codec := range []spill.Codec{spill.Gob, spill.JSON} {
+		dir := t.TempDir()
+		it := spill.Sort(readings(9, 4, 7, 1, 8, 2, 6, 3, 5, 0), byValue, spill.Config{RunSize: 3, Dir: dir, Codec: codec})
+		assert.Equal(t, 0, runFiles(t, dir))
+		v, ok := it.Next()
+		assert.True(t, ok)
+		assert.Equal(t, 0, v.(Reading).Value)
+		assert.Equal(t, 4, runFiles(t, dir))
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, values(t, it))
+		assert.Equal(t, 0, runFiles(t, dir))
+	}
+}
+
+func TestSort_IsStableAcrossRuns(t *testing.T) {
+	it := spill.Sort(readings(2, 1, 2, 1, 2, 1), byValue, spill.Config{RunSize: 2, Dir: t.TempDir()})
+	vs, err := iterators.Drain(it)
+	assert.NoError(t, err)
+	sensors := ""
+	for _, v := range vs {
+		sensors += v.(Reading).Sensor
+	}
+	assert.Equal(t, "bdface", sensors)
+}
+
+func TestSort_CancelRemovesRuns(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	it := spill.Sort(readings(5, 4, 3, 2, 1), byValue, spill.Config{RunSize: 2, Dir: dir, Context: ctx})
+	v, ok := it.Next()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v.(Reading).Value)
+
+	cancel()
+	_, ok = it.Next()
+	assert.False(t, ok)
+	assert.Equal(t, context.Canceled, iterators.Err(it))
+	assert.Equal(t, 0, runFiles(t, dir))
+}
+
+func TestSort_CloseRemovesRuns(t *testing.T) {
+	dir := t.TempDir()
+	it := spill.Sort(readings(5, 4, 3, 2, 1), byValue, spill.Config{RunSize: 2, Dir: dir})
+	it.Next()
+	assert.Equal(t, 3, runFiles(t, dir))
+
+	iterators.Close(it)
+	_, ok := it.Next()
+	assert.False(t, ok)
+	assert.NoError(t, iterators.Err(it))
+	assert.Equal(t, 0, runFiles(t, dir))
+}
+
+func TestSort_TakeRemovesRuns(t *testing.T) {
+	dir := t.TempDir()
+	it := iterators.Take(spill.Sort(readings(5, 4, 3, 2, 1), byValue, spill.Config{RunSize: 2, Dir: dir}), 1)
+	assert.Equal(t, []int{1}, values(t, it))
+	assert.Equal(t, 0, runFiles(t, dir))
+}
+
+func TestSort_MixedTypesError(t *testing.T) {
+	dir := t.TempDir()
+	input := iterators.FromSlice(reflect.ValueOf([]interface{}{1, "2", 3}))
+	it := spill.Sort(input, func(a, b interface{}) bool { return false }, spill.Config{RunSize: 2, Dir: dir})
+	_, ok := it.Next()
+	assert.False(t, ok)
+	assert.Equal(t, spill.MixedTypesError, iterators.Err(it))
+	assert.Equal(t, 0, runFiles(t, dir))
+}
+
+func TestSort_InvalidRunSizeError(t *testing.T) {
+	it := spill.Sort(readings(1), byValue, spill.Config{})
+	assert.Equal(t, spill.InvalidRunSizeError, iterators.Err(it))
+}
+
+func TestGroupBy_GroupsSpilledRunsByKey(t *testing.T) {
+	parity := func(x interface{}) interface{} { return x.(Reading).Value % 2 }
+	less := func(a, b interface{}) bool { return a.(int) < b.(int) }
+	it := spill.GroupBy(readings(3, 2, 5, 4, 1), parity, less, spill.Config{RunSize: 2, Dir: t.TempDir()})
+	assert.Equal(t, reflect.TypeOf(spill.Group{}), iterators.ElemType(it))
+	groups, err := iterators.Drain(it)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		spill.Group{Key: 0, Elements: []interface{}{Reading{"b", 2}, Reading{"d", 4}}},
+		spill.Group{Key: 1, Elements: []interface{}{Reading{"a", 3}, Reading{"c", 5}, Reading{"e", 1}}},
+	}, groups)
+}
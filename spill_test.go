@@ -0,0 +1,108 @@
+package kundalini_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+	"gitlab.com/jdbellamy/kundalini/spill"
+)
+
+func TestSort(t *testing.T) {
+
+	t.Run("should sort in memory and through spilled runs", func(t *testing.T) {
+		type Test struct {
+			input    interface{}
+			opts     []SpillOption
+			expected interface{}
+		}
+		tests := []Test{
+			{[]int{3, 1, 2}, nil, []int{1, 2, 3}},
+			{[]int{5, 3, 9, 1, 7}, []SpillOption{SpillAfter(2)}, []int{1, 3, 5, 7, 9}},
+			{[]interface{}{2, 1}, nil, []interface{}{1, 2}},
+			{&counter{from: 0, to: 6}, []SpillOption{SpillAfter(4), SpillCodec(spill.JSON)}, []interface{}{0, 1, 2, 3, 4, 5}},
+			{[]int{}, []SpillOption{SpillAfter(1)}, []int{}},
+		}
+		for _, test := range tests {
+			opts := append(test.opts, SpillDir(t.TempDir()))
+			actual, err := Wrap(test.input).Sort(byInt, opts...).Release()
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		}
+	})
+
+	t.Run("should stream the merge into later stages", func(t *testing.T) {
+		dir := t.TempDir()
+		actual, err := Wrap([]int{4, 8, 2, 6}).
+			Sort(byInt, SpillAfter(1), SpillDir(dir)).
+			Map(double).
+			Take(2).
+			Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{4, 8}, actual)
+	})
+
+	t.Run("should not sort until the result is pulled", func(t *testing.T) {
+		dir := t.TempDir()
+		src := &counter{to: 5}
+		Wrap(src).Sort(byInt, SpillAfter(2), SpillDir(dir))
+		entries, _ := os.ReadDir(dir)
+		assert.Empty(t, entries)
+		assert.Equal(t, 0, src.pulled)
+	})
+
+	t.Run("should remove runs once a later take has its elements", func(t *testing.T) {
+		dir := t.TempDir()
+		actual, err := Wrap([]int{3, 2, 1}).Sort(byInt, SpillAfter(2), SpillDir(dir)).Take(1).Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1}, actual)
+		entries, _ := os.ReadDir(dir)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("should remove runs when a partly read iterator is closed", func(t *testing.T) {
+		dir := t.TempDir()
+		it := Wrap([]int{3, 2, 1}).Sort(byInt, SpillAfter(1), SpillDir(dir)).Iterator()
+		it.Next()
+		it.(Closer).Close()
+		entries, _ := os.ReadDir(dir)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("should remove runs when cancelled", func(t *testing.T) {
+		dir := t.TempDir()
+		ctx, cancel := context.WithCancel(context.Background())
+		it := Wrap([]int{3, 2, 1}).Sort(byInt, SpillAfter(1), SpillDir(dir), SpillContext(ctx)).Iterator()
+		it.Next()
+		cancel()
+		for _, ok := it.Next(); ok; _, ok = it.Next() {
+		}
+		entries, _ := os.ReadDir(dir)
+		assert.Empty(t, entries)
+		assert.Equal(t, context.Canceled, it.(ErrIterator).Err())
+	})
+
+	t.Run("should fail on invalid options", func(t *testing.T) {
+		actual, err := Wrap([]int{1}).Sort(byInt, SpillAfter(0)).Release()
+		assert.EqualError(t, err, spill.InvalidRunSizeError.Error())
+		assert.Nil(t, actual)
+	})
+}
+
+func TestGroupBy(t *testing.T) {
+
+	t.Run("should group elements in key order", func(t *testing.T) {
+		bucket := func(x interface{}) interface{} { return x.(int) / 10 }
+		actual, err := Wrap([]int{21, 3, 14, 25, 7}).
+			GroupBy(bucket, byInt, SpillAfter(2), SpillDir(t.TempDir())).
+			Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []Group{
+			{Key: 0, Elements: []interface{}{3, 7}},
+			{Key: 1, Elements: []interface{}{14}},
+			{Key: 2, Elements: []interface{}{21, 25}},
+		}, actual)
+	})
+}
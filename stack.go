@@ -0,0 +1,27 @@
+package kundalini
+
+import "fmt"
+
+var EmptyStackError = fmt.Errorf("cannot pop an empty stack")
+
+// frame is an entry of the internal stack
+// frames are never modified once pushed, so chains branched from a common
+// parent share the frames below their own pushes without seeing each other's
+type frame struct {
+	value interface{}
+	below *frame
+	depth int
+}
+
+// push returns a stack with `v` on top of `f`
+func (f *frame) push(v interface{}) *frame {
+	return &frame{value: v, below: f, depth: f.len() + 1}
+}
+
+// len returns the number of frames in the stack topped by `f`
+func (f *frame) len() int {
+	if f == nil {
+		return 0
+	}
+	return f.depth
+}
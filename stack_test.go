@@ -0,0 +1,69 @@
+package kundalini_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+)
+
+func TestStack(t *testing.T) {
+
+	t.Run("should keep the pushes of branched chains apart", func(t *testing.T) {
+		base := Wrap([]int{0}).Push().Push().Push()
+		left := base.Map(inc).Push()
+		right := base.Map(double).Push()
+
+		l, _ := left.Pop().Pop().Release()
+		r, _ := right.Pop().Pop().Release()
+
+		assert.Equal(t, []int{0}, l)
+		assert.Equal(t, []int{0}, r)
+		leftTop, _ := left.Pop().Release()
+		rightTop, _ := right.Pop().Release()
+		assert.Equal(t, []int{1}, leftTop)
+		assert.Equal(t, []int{0}, rightTop)
+	})
+
+	t.Run("should leave the parent unchanged after a branch pops", func(t *testing.T) {
+		base := Wrap([]int{1}).Push().Map(inc).Push()
+		base.Pop().Pop()
+
+		assert.Contains(t, base.Describe(DescribeText), "depth=2")
+		top, err := base.Pop().Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2}, top)
+	})
+
+	t.Run("should raise error when the stack is empty", func(t *testing.T) {
+		_, err := Wrap([]int{1}).Push().Pop().Pop().Release()
+
+		assert.Equal(t, EmptyStackError, err)
+	})
+
+	t.Run("should share a chain across goroutines", func(t *testing.T) {
+		base := Wrap([]int{0}).Push().Push()
+		var wg sync.WaitGroup
+		results := make([]interface{}, 32)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				k := base
+				for j := 0; j < 10; j++ {
+					k = k.Map(func(x interface{}) interface{} { return x.(int) + i }).Push()
+				}
+				for j := 0; j < 10; j++ {
+					k = k.Pop()
+				}
+				results[i], _ = k.Pop().Release()
+			}(i)
+		}
+		wg.Wait()
+
+		for i := range results {
+			assert.Equal(t, []int{0}, results[i], "goroutine %d", i)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package kundalini
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/iterators"
+	"gitlab.com/jdbellamy/kundalini/stats"
+)
+
+// Summary describes the numbers wrapped by a chain
+type Summary = stats.Summary
+
+// Bucket counts the numbers of a histogram in a range
+type Bucket = stats.Bucket
+
+// Stats replaces the numbers wrapped by `k` with a single `Summary` of their
+// count, mean, variance, standard deviation, min and max
+// elements can be of any integer or float kind, a lazy `k` is summarised as
+// its elements are pulled without collecting them, NaNs and infinities fail
+// with `stats.NonFiniteError`
+func (k *K) Stats() Kundalini {
+	if k.err != nil {
+		return k
+	}
+	w := &stats.Welford{}
+	err := k.numbers(w.Add)
+	logrus.Debug(" stats: ", w.Summary())
+	if err != nil {
+		return k.fail("Stats", err)
+	}
+	return k.next("Stats", nil, []Summary{w.Summary()})
+}
+
+// Percentiles replaces the numbers wrapped by `k` with their percentiles
+// `ps`, each between 0 and 100, interpolating linearly between the closest
+// ranks, NaNs and infinities fail with `stats.NonFiniteError`
+func (k *K) Percentiles(ps ...float64) Kundalini {
+	if k.err != nil {
+		return k
+	}
+	xs, err := k.floats()
+	if err != nil {
+		return k.fail("Percentiles", err)
+	}
+	v, err := stats.Percentiles(xs, ps)
+	logrus.Debug("  pcts: ", v)
+	if err != nil {
+		return k.fail("Percentiles", err)
+	}
+	return k.next("Percentiles", nil, v)
+}
+
+// Histogram replaces the numbers wrapped by `k` with `buckets` buckets of
+// equal width covering their range and how many numbers fall into each,
+// NaNs and infinities fail with `stats.NonFiniteError`
+func (k *K) Histogram(buckets int) Kundalini {
+	if k.err != nil {
+		return k
+	}
+	xs, err := k.floats()
+	if err != nil {
+		return k.fail("Histogram", err)
+	}
+	v, err := stats.Histogram(xs, buckets)
+	logrus.Debug("  hist: ", v)
+	if err != nil {
+		return k.fail("Histogram", err)
+	}
+	return k.next("Histogram", nil, v)
+}
+
+// floats collects the numbers wrapped by `k` as float64s
+func (k *K) floats() ([]float64, error) {
+	xs := make([]float64, 0)
+	err := k.numbers(func(x float64) {
+		xs = append(xs, x)
+	})
+	return xs, err
+}
+
+// numbers passes each number wrapped by `k` to `fn` as a float64, pulling a
+// lazy `k` one element at a time, it stops at the first that is not finite
+func (k *K) numbers(fn func(float64)) error {
+	if _, ok := k.wrapped.(Iterator); !ok {
+		switch reflect.TypeOf(k.wrapped).Kind() {
+		case reflect.Slice, reflect.Array:
+		default:
+			return UnsupportedWrappedTypeError
+		}
+	}
+	it := k.Iterator()
+	defer iterators.Close(it)
+	for v, ok := it.Next(); ok; v, ok = it.Next() {
+		x, err := stats.Float(v)
+		if err != nil {
+			return err
+		}
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return stats.NonFiniteError
+		}
+		fn(x)
+	}
+	if e, ok := it.(ErrIterator); ok {
+		return e.Err()
+	}
+	return nil
+}
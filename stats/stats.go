@@ -0,0 +1,163 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+var NotNumericError = fmt.Errorf("elements must be integers or floats")
+var EmptyInputError = fmt.Errorf("input must hold at least one element")
+var InvalidPercentileError = fmt.Errorf("percentiles must be between 0 and 100")
+var InvalidBucketCountError = fmt.Errorf("bucket count must be at least 1")
+var NonFiniteError = fmt.Errorf("numbers must be finite")
+
+// Float converts any integer or float kind to a float64
+func Float(v interface{}) (float64, error) {
+	x := reflect.ValueOf(v)
+	for x.Kind() == reflect.Interface || x.Kind() == reflect.Ptr {
+		if x.IsNil() {
+			return 0, NotNumericError
+		}
+		x = x.Elem()
+	}
+	switch k := x.Kind(); {
+	case k >= reflect.Int && k <= reflect.Int64:
+		return float64(x.Int()), nil
+	case k >= reflect.Uint && k <= reflect.Uintptr:
+		return float64(x.Uint()), nil
+	case k == reflect.Float32 || k == reflect.Float64:
+		return x.Float(), nil
+	}
+	return 0, NotNumericError
+}
+
+// Summary describes a set of numbers, every field but `Count` is zero when
+// it is empty
+// `Variance` and `Stddev` are of the sample, they are zero for one number
+type Summary struct {
+	Count    int
+	Mean     float64
+	Variance float64
+	Stddev   float64
+	Min      float64
+	Max      float64
+}
+
+// Welford accumulates a `Summary` one number at a time without holding on
+// to them, using Welford's algorithm for the variance
+type Welford struct {
+	n    int
+	mean float64
+	m2   float64
+	min  float64
+	max  float64
+}
+
+// Add accumulates `x`, a NaN or an infinity makes the summary meaningless
+func (w *Welford) Add(x float64) {
+	w.n++
+	if w.n == 1 || x < w.min {
+		w.min = x
+	}
+	if w.n == 1 || x > w.max {
+		w.max = x
+	}
+	d := x - w.mean
+	w.mean += d / float64(w.n)
+	w.m2 += d * (x - w.mean)
+}
+
+// Summary describes the numbers accumulated so far
+func (w *Welford) Summary() Summary {
+	s := Summary{Count: w.n, Mean: w.mean, Min: w.min, Max: w.max}
+	if w.n > 1 {
+		s.Variance = w.m2 / float64(w.n-1)
+		s.Stddev = math.Sqrt(s.Variance)
+	}
+	return s
+}
+
+// finite fails with `NonFiniteError` when `xs` holds a NaN or an infinity
+func finite(xs []float64) error {
+	for _, x := range xs {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return NonFiniteError
+		}
+	}
+	return nil
+}
+
+// Percentiles returns the percentiles `ps` of `xs`, interpolating linearly
+// between the closest ranks, `xs` is sorted in place and must be finite
+func Percentiles(xs []float64, ps []float64) ([]float64, error) {
+	if len(xs) == 0 {
+		return nil, EmptyInputError
+	}
+	if err := finite(xs); err != nil {
+		return nil, err
+	}
+	sort.Float64s(xs)
+	r := make([]float64, len(ps))
+	for i, p := range ps {
+		if p < 0 || p > 100 || math.IsNaN(p) {
+			return nil, InvalidPercentileError
+		}
+		rank := p / 100 * float64(len(xs)-1)
+		lo := int(math.Floor(rank))
+		hi := int(math.Ceil(rank))
+		r[i] = xs[lo] + (xs[hi]-xs[lo])*(rank-float64(lo))
+	}
+	return r, nil
+}
+
+// Bucket counts the numbers from `Low` up to but excluding `High`, the last
+// bucket of a histogram also counts its `High`
+type Bucket struct {
+	Low   float64
+	High  float64
+	Count int
+}
+
+// Histogram splits the range of `xs` into `n` buckets of equal width and
+// counts the numbers falling into each, `xs` must be finite
+// when every number is the same they are all counted in the first bucket
+func Histogram(xs []float64, n int) ([]Bucket, error) {
+	if n < 1 {
+		return nil, InvalidBucketCountError
+	}
+	if len(xs) == 0 {
+		return nil, EmptyInputError
+	}
+	if err := finite(xs); err != nil {
+		return nil, err
+	}
+	min, max := xs[0], xs[0]
+	for _, x := range xs {
+		min = math.Min(min, x)
+		max = math.Max(max, x)
+	}
+
+	width := (max - min) / float64(n)
+	r := make([]Bucket, n)
+	for i := range r {
+		r[i].Low = min + width*float64(i)
+		r[i].High = min + width*float64(i+1)
+	}
+	r[n-1].High = max
+	for _, x := range xs {
+		i := 0
+		if width > 0 {
+			i = int((x - min) / width)
+		}
+		switch {
+		case i < 0:
+			i = 0
+		case i >= n:
+			i = n - 1
+		}
+		r[i].Count++
+	}
+	return r, nil
+}
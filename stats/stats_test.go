@@ -0,0 +1,93 @@
+package stats_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.com/jdbellamy/kundalini/stats"
+)
+
+func TestFloat_ConvertsNumericKinds(t *testing.T) {
+	type celsius float32
+	n := 7
+	for _, v := range []interface{}{int8(7), uint64(7), float32(7), celsius(7), &n} {
+		x, err := stats.Float(v)
+		assert.NoError(t, err)
+		assert.Equal(t, 7.0, x)
+	}
+}
+
+func TestFloat_NotNumericError(t *testing.T) {
+	var nilInt *int
+	for _, v := range []interface{}{"7", nil, nilInt, []int{7}} {
+		_, err := stats.Float(v)
+		assert.EqualError(t, err, stats.NotNumericError.Error())
+	}
+}
+
+func TestWelford_MatchesTwoPassVariance(t *testing.T) {
+	xs := []float64{1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16}
+	w := &stats.Welford{}
+	for _, x := range xs {
+		w.Add(x)
+	}
+	s := w.Summary()
+	assert.Equal(t, 4, s.Count)
+	assert.Equal(t, 1e9+10, s.Mean)
+	assert.InDelta(t, 30, s.Variance, 1e-6)
+	assert.InDelta(t, math.Sqrt(30), s.Stddev, 1e-6)
+	assert.Equal(t, 1e9+4, s.Min)
+	assert.Equal(t, 1e9+16, s.Max)
+}
+
+func TestWelford_SingleNumberHasNoVariance(t *testing.T) {
+	w := &stats.Welford{}
+	w.Add(-3)
+	assert.Equal(t, stats.Summary{Count: 1, Mean: -3, Min: -3, Max: -3}, w.Summary())
+}
+
+func TestPercentiles_InterpolatesBetweenRanks(t *testing.T) {
+	actual, err := stats.Percentiles([]float64{40, 10, 30, 20}, []float64{0, 25, 50, 100})
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{10, 17.5, 25, 40}, actual)
+}
+
+func TestPercentiles_InvalidPercentileError(t *testing.T) {
+	for _, p := range []float64{-1, 101, math.NaN()} {
+		_, err := stats.Percentiles([]float64{1}, []float64{p})
+		assert.EqualError(t, err, stats.InvalidPercentileError.Error())
+	}
+}
+
+func TestPercentiles_NonFiniteError(t *testing.T) {
+	for _, x := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		_, err := stats.Percentiles([]float64{1, x, 3}, []float64{50})
+		assert.EqualError(t, err, stats.NonFiniteError.Error())
+	}
+}
+
+func TestHistogram_CountsMaxInLastBucket(t *testing.T) {
+	actual, err := stats.Histogram([]float64{0, 1, 2, 5, 9, 10}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []stats.Bucket{{Low: 0, High: 5, Count: 3}, {Low: 5, High: 10, Count: 3}}, actual)
+}
+
+func TestHistogram_SameNumbersInFirstBucket(t *testing.T) {
+	actual, err := stats.Histogram([]float64{3, 3}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []stats.Bucket{{Low: 3, High: 3, Count: 2}, {Low: 3, High: 3, Count: 0}}, actual)
+}
+
+func TestHistogram_InvalidBucketCountError(t *testing.T) {
+	_, err := stats.Histogram([]float64{1}, 0)
+	assert.EqualError(t, err, stats.InvalidBucketCountError.Error())
+}
+
+func TestHistogram_NonFiniteError(t *testing.T) {
+	for _, x := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		_, err := stats.Histogram([]float64{1, x, 3}, 2)
+		assert.EqualError(t, err, stats.NonFiniteError.Error())
+	}
+}
@@ -0,0 +1,113 @@
+package kundalini_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+	"gitlab.com/jdbellamy/kundalini/stats"
+)
+
+func TestStats(t *testing.T) {
+
+	t.Run("should summarise any numeric kind", func(t *testing.T) {
+		type Test struct {
+			input    interface{}
+			expected []Summary
+		}
+		tests := []Test{
+			{[]int{2, 4, 4, 4, 5, 5, 7, 9}, []Summary{{Count: 8, Mean: 5, Variance: 32.0 / 7, Stddev: 2.138089935299395, Min: 2, Max: 9}}},
+			{[3]uint8{1, 2, 3}, []Summary{{Count: 3, Mean: 2, Variance: 1, Stddev: 1, Min: 1, Max: 3}}},
+			{[]interface{}{1.5, int64(2), float32(2.5)}, []Summary{{Count: 3, Mean: 2, Variance: 0.25, Stddev: 0.5, Min: 1.5, Max: 2.5}}},
+			{[]float64{}, []Summary{{}}},
+		}
+		for _, test := range tests {
+			actual, err := Wrap(test.input).Stats().Release()
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		}
+	})
+
+	t.Run("should summarise a lazy chain as it is pulled", func(t *testing.T) {
+		src := &counter{to: 5}
+		actual, err := Wrap(src).Map(double).Stats().Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []Summary{{Count: 5, Mean: 4, Variance: 10, Stddev: 3.1622776601683795, Min: 0, Max: 8}}, actual)
+		assert.Equal(t, 5, src.pulled)
+	})
+
+	t.Run("should fail on non-numeric elements", func(t *testing.T) {
+		actual, err := Wrap([]string{"1"}).Stats().Release()
+		assert.EqualError(t, err, stats.NotNumericError.Error())
+		assert.Nil(t, actual)
+	})
+
+	t.Run("should fail on non-finite numbers", func(t *testing.T) {
+		for _, x := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+			actual, err := Wrap([]float64{1, x, 3}).Stats().Release()
+			assert.EqualError(t, err, stats.NonFiniteError.Error())
+			assert.Nil(t, actual)
+		}
+	})
+}
+
+func TestPercentiles(t *testing.T) {
+
+	t.Run("should interpolate percentiles", func(t *testing.T) {
+		type Test struct {
+			input    interface{}
+			ps       []float64
+			expected []float64
+		}
+		tests := []Test{
+			{[]int{15, 20, 35, 40, 50}, []float64{0, 50, 100}, []float64{15, 35, 50}},
+			{[]int{1, 2, 3, 4}, []float64{25, 90}, []float64{1.75, 3.7}},
+			{&counter{from: 1, to: 11}, []float64{50}, []float64{5.5}},
+			{[]int{1}, []float64{}, []float64{}},
+		}
+		for _, test := range tests {
+			actual, err := Wrap(test.input).Percentiles(test.ps...).Release()
+			assert.NoError(t, err)
+			assert.InDeltaSlice(t, test.expected, actual, 1e-9)
+		}
+	})
+
+	t.Run("should not reorder the input", func(t *testing.T) {
+		input := []float64{3, 1, 2}
+		_, err := Wrap(input).Percentiles(50).Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []float64{3, 1, 2}, input)
+	})
+
+	t.Run("should fail on empty input and invalid percentiles", func(t *testing.T) {
+		_, err := Wrap([]int{}).Percentiles(50).Release()
+		assert.EqualError(t, err, stats.EmptyInputError.Error())
+		_, err = Wrap([]int{1}).Percentiles(150).Release()
+		assert.EqualError(t, err, stats.InvalidPercentileError.Error())
+	})
+}
+
+func TestHistogram(t *testing.T) {
+
+	t.Run("should count numbers in equal buckets", func(t *testing.T) {
+		actual, err := Wrap(&counter{to: 10}).Histogram(3).Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []Bucket{
+			{Low: 0, High: 3, Count: 3}, {Low: 3, High: 6, Count: 3}, {Low: 6, High: 9, Count: 4},
+		}, actual)
+	})
+
+	t.Run("should fail on invalid bucket counts", func(t *testing.T) {
+		actual, err := Wrap([]int{1, 2}).Histogram(0).Release()
+		assert.EqualError(t, err, stats.InvalidBucketCountError.Error())
+		assert.Nil(t, actual)
+	})
+
+	t.Run("should fail on non-finite numbers", func(t *testing.T) {
+		_, err := Wrap([]float64{1, math.Inf(1), 3}).Histogram(2).Release()
+		assert.EqualError(t, err, stats.NonFiniteError.Error())
+		_, err = Wrap([]float64{1, math.NaN(), 3}).Percentiles(50).Release()
+		assert.EqualError(t, err, stats.NonFiniteError.Error())
+	})
+}
@@ -0,0 +1,45 @@
+package kundalini
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var NotAStringSequenceError = fmt.Errorf("wrapped elements cannot be joined into a string")
+
+var runeT = reflect.TypeOf(rune(0))
+var byteT = reflect.TypeOf(byte(0))
+
+// WrapRunes wraps the runes of `s` in an instance of `k`
+func WrapRunes(s string) Kundalini {
+	return Wrap([]rune(s))
+}
+
+// WrapBytes wraps the bytes of `s` in an instance of `k`
+func WrapBytes(s string) Kundalini {
+	return Wrap([]byte(s))
+}
+
+// ReleaseString returns the rune or byte elements wrapped by `k` as a string
+// `val` is always empty when `err` is populated
+func (k *K) ReleaseString() (val string, err error) {
+	k = k.drain()
+	if k.err != nil {
+		return "", k.err
+	}
+	v := reflect.ValueOf(k.wrapped)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		switch v.Type().Elem() {
+		case runeT:
+			runes := make([]rune, v.Len())
+			reflect.Copy(reflect.ValueOf(runes), v)
+			return string(runes), nil
+		case byteT:
+			bytes := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(bytes), v)
+			return string(bytes), nil
+		}
+	}
+	return "", NotAStringSequenceError
+}
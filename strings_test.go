@@ -0,0 +1,77 @@
+package kundalini_test
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+)
+
+func TestReleaseString(t *testing.T) {
+
+	t.Run("should turn mapped and filtered runes back into a string", func(t *testing.T) {
+		upper := func(x interface{}) interface{} { return unicode.ToUpper(x.(rune)) }
+		notSpace := func(x interface{}) bool { return !unicode.IsSpace(x.(rune)) }
+
+		actual, err := WrapRunes("∆ delta ∆").
+			Filter(notSpace).
+			Map(upper).
+			ReleaseString()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "∆DELTA∆", actual)
+	})
+
+	t.Run("should turn bytes back into a string", func(t *testing.T) {
+		ascii := func(x interface{}) bool { return x.(byte) < 0x80 }
+
+		actual, err := WrapBytes("a∆b").
+			Filter(ascii).
+			ReleaseString()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ab", actual)
+	})
+
+	t.Run("should turn byte arrays into a string", func(t *testing.T) {
+		actual, err := Wrap([3]byte{'k', 'u', 'n'}).ReleaseString()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "kun", actual)
+	})
+
+	t.Run("should raise error when elements are not runes or bytes", func(t *testing.T) {
+		type Test struct {
+			input interface{}
+			err   error
+		}
+
+		tests := []Test{{
+			input: []int{1},
+			err:   NotAStringSequenceError,
+		}, {
+			input: []string{"a"},
+			err:   NotAStringSequenceError,
+		}, {
+			input: "a",
+			err:   NotAStringSequenceError,
+		}}
+
+		for _, tt := range tests {
+			actual, err := Wrap(tt.input).ReleaseString()
+
+			assert.EqualError(t, err, tt.err.Error())
+			assert.Equal(t, "", actual)
+		}
+	})
+
+	t.Run("should forward received error", func(t *testing.T) {
+		var noop Fn = func(x interface{}) interface{} { return nil }
+
+		actual, err := Wrap(0).Map(noop).ReleaseString()
+
+		assert.EqualError(t, err, UnsupportedWrappedTypeError.Error())
+		assert.Equal(t, "", actual)
+	})
+}
@@ -0,0 +1,65 @@
+package kundalini
+
+import (
+	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/iterators"
+)
+
+// Clock tells the time and waits, it can be replaced to test timing
+type Clock = iterators.Clock
+
+// SystemClock is the `Clock` of the running system
+var SystemClock = iterators.SystemClock
+
+// BatchFn maps a chunk of elements to any number of results
+type BatchFn func([]interface{}) ([]interface{}, error)
+
+// MapBatched calls `fn` with chunks of up to `size` elements of `k` and
+// replaces them with the elements it returns
+// a chunk `fn` fails on is rejected in dead-letter mode, otherwise its
+// `*Rejection` becomes the chain's error
+// the result is lazy when `k` is, `opts` can retry and time out chunks
+func (k *K) MapBatched(size int, fn BatchFn, opts ...StageOption) Kundalini {
+	if k.err != nil {
+		return k
+	}
+	stage := k.stage
+	call := withOptions(k.serialFnE(func(v interface{}) (interface{}, error) {
+		return fn(v.([]interface{}))
+	}), opts)
+	staged := func(batch []interface{}) ([]interface{}, error) {
+		out, err := call(batch)
+		if err != nil {
+			return nil, &Rejection{Stage: stage, Value: batch, Err: err}
+		}
+		r, _ := out.([]interface{})
+		return r, nil
+	}
+
+	it := iterators.Batch(k.Iterator(), size, staged, k.reject())
+	if _, lazy := k.wrapped.(Iterator); lazy {
+		logrus.Debug(" batch: ", it)
+		return k.next("MapBatched", fn, it)
+	}
+	v, err := iterators.Drain(it)
+	logrus.Debug(" batch: ", v)
+	if err != nil {
+		return k.fail("MapBatched", err)
+	}
+	return k.next("MapBatched", fn, v)
+}
+
+// Throttle paces the elements of `k` with a token bucket that holds up to
+// `burst` tokens and earns `rate` tokens a second, each element pulled from
+// `k` takes a token
+// the result is lazy so that the stages after it are paced too, `opts` can
+// replace the clock it waits on
+func (k *K) Throttle(rate float64, burst int, opts ...StageOption) Kundalini {
+	if k.err != nil {
+		return k
+	}
+	c := newStageConfig(opts)
+	it := iterators.Throttle(k.Iterator(), rate, burst, c.clock)
+	logrus.Debug("thrtle: ", it)
+	return k.next("Throttle", nil, it)
+}
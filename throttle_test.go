@@ -0,0 +1,140 @@
+package kundalini_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+	"gitlab.com/jdbellamy/kundalini/iterators"
+)
+
+// fakeClock moves time forward only when asked to sleep
+type fakeClock struct {
+	now   time.Time
+	slept []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.slept = append(c.slept, d)
+	c.now = c.now.Add(d)
+}
+
+func TestMapBatched(t *testing.T) {
+	sum := func(batch []interface{}) ([]interface{}, error) {
+		total := 0
+		for _, x := range batch {
+			total += x.(int)
+		}
+		return []interface{}{total}, nil
+	}
+
+	t.Run("should call fn with chunks of elements", func(t *testing.T) {
+		actual, err := Wrap([]int{1, 2, 3, 4, 5}).MapBatched(2, sum).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{3, 7, 5}, actual)
+	})
+
+	t.Run("should pull chunks lazily", func(t *testing.T) {
+		src := &counter{to: 100}
+		it := Wrap(src).MapBatched(10, sum).Iterator()
+
+		v, _ := it.Next()
+
+		assert.Equal(t, 45, v)
+		assert.Equal(t, 10, src.pulled)
+	})
+
+	t.Run("should reject failing chunks in dead-letter mode", func(t *testing.T) {
+		store := func(batch []interface{}) ([]interface{}, error) {
+			if batch[0].(int) == 3 {
+				return nil, errors.New("store is full")
+			}
+			return batch, nil
+		}
+		k := Wrap([]int{1, 2, 3, 4, 5}).DeadLetter().MapBatched(2, store)
+
+		actual, err := k.Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{1, 2, 5}, actual)
+		assert.Equal(t, []Rejection{{Stage: 0, Value: []interface{}{3, 4}, Err: errors.New("store is full")}}, k.Rejected())
+	})
+
+	t.Run("should let a later take close the source", func(t *testing.T) {
+		src := &closing{counter: counter{to: 10}}
+		same := func(batch []interface{}) ([]interface{}, error) { return batch, nil }
+
+		actual, err := Wrap(src).MapBatched(2, same).Take(3).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{0, 1, 2}, actual)
+		assert.True(t, src.closed)
+	})
+
+	t.Run("should raise error when size is not positive", func(t *testing.T) {
+		_, err := Wrap([]int{1}).MapBatched(0, sum).Release()
+
+		assert.Equal(t, iterators.InvalidBatchSizeError, err)
+	})
+
+	t.Run("should forward received error", func(t *testing.T) {
+		_, err := Wrap(0).Types().MapBatched(1, sum).Release()
+
+		assert.Equal(t, UnsupportedWrappedTypeError, err)
+	})
+}
+
+func TestThrottle(t *testing.T) {
+
+	t.Run("should let a burst through before pacing", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+
+		actual, err := Wrap([]int{1, 2, 3, 4, 5}).
+			Throttle(10, 2, WithClock(clock)).
+			Map(func(x interface{}) interface{} { return x.(int) * 2 }).
+			Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{2, 4, 6, 8, 10}, actual)
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond}, clock.slept)
+	})
+
+	t.Run("should earn tokens back while idle", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		it := Wrap([]int{1, 2, 3}).Throttle(1, 2, WithClock(clock)).Iterator()
+
+		it.Next()
+		it.Next()
+		clock.now = clock.now.Add(time.Second)
+		it.Next()
+
+		assert.Empty(t, clock.slept)
+	})
+
+	t.Run("should keep the element type", func(t *testing.T) {
+		actual, err := Wrap([]int{1, 2, 3}).Throttle(1000, 3).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, actual)
+	})
+
+	t.Run("should let a later take close the source", func(t *testing.T) {
+		src := &closing{counter: counter{to: 10}}
+
+		actual, err := Wrap(src).Throttle(1e6, 10).Take(2).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{0, 1}, actual)
+		assert.True(t, src.closed)
+	})
+
+	t.Run("should raise error when rate is not positive", func(t *testing.T) {
+		_, err := Wrap([]int{1}).Throttle(0, 1).Release()
+
+		assert.Equal(t, iterators.InvalidRateError, err)
+	})
+}
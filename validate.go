@@ -0,0 +1,59 @@
+package kundalini
+
+import (
+	"reflect"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/validate"
+)
+
+// Rule checks a single element and returns nil when it is valid
+type Rule = validate.Rule
+
+// Invalid is an element that broke one or more rules
+type Invalid = validate.Invalid
+
+// ValidationErrors collects every invalid element of a chain
+type ValidationErrors = validate.Errors
+
+// Rules configures the checks made by `Validate`
+type Rules struct {
+	// Checks are run against every element, see the `validate` package
+	Checks []Rule
+	// OnInvalid receives each element that breaks any of `Checks`, which is
+	// then dropped from the chain
+	// when nil the invalid elements are rejected in dead-letter mode, and
+	// otherwise become the chain's `ValidationErrors`
+	OnInvalid func(*Invalid)
+}
+
+// Validate checks each element of `k` against `rules`
+func (k *K) Validate(rules Rules) Kundalini {
+	k = k.drain()
+	if k.err != nil {
+		return k
+	}
+	switch reflect.TypeOf(k.wrapped).Kind() {
+	case reflect.Slice, reflect.Array:
+		v, invalid := validate.Check(reflect.ValueOf(k.wrapped), rules.Checks)
+		logrus.Debug(" check: ", v)
+		if invalid == nil {
+			return k.next("Validate", nil, v)
+		}
+		onInvalid := rules.OnInvalid
+		if onInvalid == nil && k.letters != nil {
+			reject := k.reject()
+			onInvalid = func(inv *Invalid) {
+				reject(inv.Value, &Rejection{Stage: k.stage, Value: inv.Value, Err: inv})
+			}
+		}
+		if onInvalid == nil {
+			return k.fail("Validate", invalid)
+		}
+		for _, inv := range invalid {
+			onInvalid(inv)
+		}
+		return k.next("Validate", nil, v)
+	}
+	return k.fail("Validate", UnsupportedWrappedTypeError)
+}
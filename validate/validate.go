@@ -0,0 +1,260 @@
+package validate
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gitlab.com/jdbellamy/kundalini/slices"
+)
+
+var ZeroValueError = fmt.Errorf("value must not be the zero value")
+var OutOfRangeError = fmt.Errorf("value is out of range")
+var PatternMismatchError = fmt.Errorf("value does not match pattern")
+var NotNumericError = fmt.Errorf("value is not numeric")
+var NotAStringError = fmt.Errorf("value is not a string")
+var InvalidTagError = fmt.Errorf("malformed validate tag")
+
+// Rule checks a single element and returns nil when it is valid
+type Rule func(e interface{}) error
+
+// FieldError reports the field of an element that broke a rule
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+// Unwrap returns the error of the rule so that `errors.Is` matches it
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// Invalid is an element that broke one or more rules
+type Invalid struct {
+	Index int
+	Value interface{}
+	Errs  []error
+}
+
+func (e *Invalid) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("element %d: %s", e.Index, strings.Join(msgs, ", "))
+}
+
+// Unwrap returns the errors of the broken rules so that `errors.Is` matches
+// any of them
+func (e *Invalid) Unwrap() []error { return e.Errs }
+
+// Errors collects every invalid element of a sequence
+type Errors []*Invalid
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, inv := range e {
+		msgs[i] = inv.Error()
+	}
+	return fmt.Sprintf("%d invalid elements: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns each invalid element so that `errors.Is` matches the errors
+// of any of them
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, inv := range e {
+		errs[i] = inv
+	}
+	return errs
+}
+
+// field returns the field at `path` in `e`, an empty `path` is `e` itself
+func field(e interface{}, path string) (reflect.Value, error) {
+	v := reflect.ValueOf(e)
+	if path == "" || !v.IsValid() {
+		return v, nil
+	}
+	return slices.FieldByPath(v, path)
+}
+
+// NonZero requires the field at `path` to hold a non-zero value
+func NonZero(path string) Rule {
+	return func(e interface{}) error {
+		v, err := field(e, path)
+		if err != nil {
+			return &FieldError{Field: path, Err: err}
+		}
+		return nonZero(path, v)
+	}
+}
+
+// Range requires the numeric field at `path` to lie within [`min`, `max`],
+// NaN lies within no range
+func Range(path string, min, max float64) Rule {
+	return func(e interface{}) error {
+		v, err := field(e, path)
+		if err != nil {
+			return &FieldError{Field: path, Err: err}
+		}
+		return inRange(path, v, &min, &max)
+	}
+}
+
+// Match requires the string field at `path` to match `re`
+func Match(path string, re *regexp.Regexp) Rule {
+	return func(e interface{}) error {
+		v, err := field(e, path)
+		if err != nil {
+			return &FieldError{Field: path, Err: err}
+		}
+		return match(path, v, re)
+	}
+}
+
+// Tags checks each exported field of a struct element against its `validate`
+// tag, a comma separated list of `nonzero`, `min=N`, `max=N` and `regex=RE`
+// `regex` takes the rest of the tag and so must come last
+func Tags() Rule {
+	return func(e interface{}) error {
+		v := reflect.ValueOf(e)
+		for v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return &FieldError{Err: slices.NotAStructError}
+		}
+		for i := 0; i < v.NumField(); i++ {
+			sf := v.Type().Field(i)
+			tag, ok := sf.Tag.Lookup("validate")
+			if !ok || sf.PkgPath != "" {
+				continue
+			}
+			if err := checkTag(sf.Name, v.Field(i), tag); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func checkTag(name string, v reflect.Value, tag string) error {
+	var min, max *float64
+	for tag != "" {
+		var opt string
+		if strings.HasPrefix(tag, "regex=") {
+			opt, tag = tag, ""
+		} else if i := strings.Index(tag, ","); i >= 0 {
+			opt, tag = tag[:i], tag[i+1:]
+		} else {
+			opt, tag = tag, ""
+		}
+
+		key, arg := opt, ""
+		if i := strings.Index(opt, "="); i >= 0 {
+			key, arg = opt[:i], opt[i+1:]
+		}
+		switch key {
+		case "nonzero":
+			if err := nonZero(name, v); err != nil {
+				return err
+			}
+		case "min", "max":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return &FieldError{Field: name, Err: InvalidTagError}
+			}
+			if key == "min" {
+				min = &n
+			} else {
+				max = &n
+			}
+		case "regex":
+			re, err := regexp.Compile(arg)
+			if err != nil {
+				return &FieldError{Field: name, Err: InvalidTagError}
+			}
+			if err := match(name, v, re); err != nil {
+				return err
+			}
+		default:
+			return &FieldError{Field: name, Err: InvalidTagError}
+		}
+	}
+	if min != nil || max != nil {
+		return inRange(name, v, min, max)
+	}
+	return nil
+}
+
+func nonZero(name string, v reflect.Value) error {
+	if !v.IsValid() || v.IsZero() {
+		return &FieldError{Field: name, Err: ZeroValueError}
+	}
+	return nil
+}
+
+// inRange checks `v` against the bounds that are not nil
+func inRange(name string, v reflect.Value, min, max *float64) error {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	var n float64
+	switch k := v.Kind(); {
+	case k >= reflect.Int && k <= reflect.Int64:
+		n = float64(v.Int())
+	case k >= reflect.Uint && k <= reflect.Uintptr:
+		n = float64(v.Uint())
+	case k == reflect.Float32 || k == reflect.Float64:
+		n = v.Float()
+	default:
+		return &FieldError{Field: name, Err: NotNumericError}
+	}
+	if math.IsNaN(n) || min != nil && n < *min || max != nil && n > *max {
+		return &FieldError{Field: name, Err: OutOfRangeError}
+	}
+	return nil
+}
+
+func match(name string, v reflect.Value, re *regexp.Regexp) error {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.String {
+		return &FieldError{Field: name, Err: NotAStringError}
+	}
+	if !re.MatchString(v.String()) {
+		return &FieldError{Field: name, Err: PatternMismatchError}
+	}
+	return nil
+}
+
+// Check runs `rules` against each element of the slice or array `s`
+// it returns a slice of the valid elements along with the invalid ones
+func Check(s reflect.Value, rules []Rule) (interface{}, Errors) {
+	valid := reflect.MakeSlice(reflect.SliceOf(s.Type().Elem()), 0, s.Len())
+	var invalid Errors
+	for i := 0; i < s.Len(); i++ {
+		e := s.Index(i).Interface()
+		var errs []error
+		for _, rule := range rules {
+			if err := rule(e); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if errs != nil {
+			invalid = append(invalid, &Invalid{Index: i, Value: e, Errs: errs})
+			continue
+		}
+		valid = reflect.Append(valid, s.Index(i))
+	}
+	return valid.Interface(), invalid
+}
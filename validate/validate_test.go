@@ -0,0 +1,163 @@
+package validate_test
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "gitlab.com/jdbellamy/kundalini"
+	"gitlab.com/jdbellamy/kundalini/slices"
+	"gitlab.com/jdbellamy/kundalini/validate"
+)
+
+type Signup struct {
+	Email string `validate:"nonzero,regex=^[^@,]+@[^@]+$"`
+	Age   int    `validate:"min=13,max=130"`
+	Plan  *Plan
+	notes string
+}
+
+type Plan struct {
+	Seats int `json:"seats"`
+}
+
+var signups = []Signup{
+	{Email: "ana@example.com", Age: 34, Plan: &Plan{Seats: 3}},
+	{Email: "", Age: 9},
+	{Email: "cy-at-example.com", Age: 41, Plan: &Plan{Seats: 0}},
+}
+
+func invalid(t *testing.T, k Kundalini) ValidationErrors {
+	_, err := k.Release()
+	errs, ok := err.(ValidationErrors)
+	assert.True(t, ok, "expected ValidationErrors, got %v", err)
+	return errs
+}
+
+func TestNonZero_Field(t *testing.T) {
+	errs := invalid(t, Wrap(signups).Validate(Rules{Checks: []Rule{validate.NonZero("Email")}}))
+
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, 1, errs[0].Index)
+		assert.Equal(t, signups[1], errs[0].Value)
+		assert.Equal(t, []error{&validate.FieldError{Field: "Email", Err: validate.ZeroValueError}}, errs[0].Errs)
+	}
+}
+
+func TestNonZero_NestedFieldThroughNilPointer(t *testing.T) {
+	errs := invalid(t, Wrap(signups).Validate(Rules{Checks: []Rule{validate.NonZero("Plan.seats")}}))
+
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, 1, errs[0].Index)
+		assert.Equal(t, 2, errs[1].Index)
+	}
+}
+
+func TestNonZero_Element(t *testing.T) {
+	errs := invalid(t, Wrap([]interface{}{1, nil, "", "a"}).Validate(Rules{Checks: []Rule{validate.NonZero("")}}))
+
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, 1, errs[0].Index)
+		assert.Equal(t, 2, errs[1].Index)
+	}
+}
+
+func TestRange_Element(t *testing.T) {
+	actual, err := Wrap([5]int{-1, 0, 5, 10, 11}).
+		Validate(Rules{Checks: []Rule{validate.Range("", 0, 10)}, OnInvalid: func(*Invalid) {}}).
+		Release()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 5, 10}, actual)
+}
+
+func TestRange_NaN(t *testing.T) {
+	errs := invalid(t, Wrap([]float64{1, math.NaN()}).Validate(Rules{Checks: []Rule{validate.Range("", 0, 10)}}))
+
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, 1, errs[0].Index)
+	}
+}
+
+func TestRange_NotNumeric(t *testing.T) {
+	errs := invalid(t, Wrap([]string{"a"}).Validate(Rules{Checks: []Rule{validate.Range("", 0, 1)}}))
+
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, validate.NotNumericError, errs[0].Errs[0].(*validate.FieldError).Err)
+	}
+}
+
+func TestMatch_Field(t *testing.T) {
+	re := regexp.MustCompile(`@example\.com$`)
+	errs := invalid(t, Wrap(signups).Validate(Rules{Checks: []Rule{validate.Match("Email", re)}}))
+
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, validate.PatternMismatchError, errs[0].Errs[0].(*validate.FieldError).Err)
+		assert.Equal(t, 2, errs[1].Index)
+	}
+}
+
+func TestRules_UnknownField(t *testing.T) {
+	errs := invalid(t, Wrap(signups[:1]).Validate(Rules{Checks: []Rule{validate.NonZero("Missing")}}))
+
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, slices.UnknownFieldError, errs[0].Errs[0].(*validate.FieldError).Err)
+	}
+}
+
+func TestTags_Struct(t *testing.T) {
+	errs := invalid(t, Wrap(signups).Validate(Rules{Checks: []Rule{validate.Tags()}}))
+
+	if assert.Len(t, errs, 2) {
+		assert.EqualError(t, errs[0], "element 1: Email: value must not be the zero value")
+		assert.EqualError(t, errs[1], "element 2: Email: value does not match pattern")
+	}
+}
+
+func TestTags_RangeOnly(t *testing.T) {
+	type Player struct {
+		Level int `validate:"max=10"`
+	}
+	errs := invalid(t, Wrap([]*Player{{Level: 3}, {Level: 12}}).Validate(Rules{Checks: []Rule{validate.Tags()}}))
+
+	if assert.Len(t, errs, 1) {
+		assert.EqualError(t, errs[0], "element 1: Level: value is out of range")
+	}
+}
+
+func TestTags_Malformed(t *testing.T) {
+	type Player struct {
+		Level int `validate:"positive"`
+	}
+	errs := invalid(t, Wrap([]Player{{Level: 3}}).Validate(Rules{Checks: []Rule{validate.Tags()}}))
+
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, validate.InvalidTagError, errs[0].Errs[0].(*validate.FieldError).Err)
+	}
+}
+
+func TestTags_NotAStruct(t *testing.T) {
+	errs := invalid(t, Wrap([]int{1}).Validate(Rules{Checks: []Rule{validate.Tags()}}))
+
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, slices.NotAStructError, errs[0].Errs[0].(*validate.FieldError).Err)
+	}
+}
+
+func TestErrors_Message(t *testing.T) {
+	_, err := Wrap(signups).
+		Validate(Rules{Checks: []Rule{validate.NonZero("Email"), validate.Range("Age", 13, 130)}}).
+		Release()
+
+	assert.EqualError(t, err, "1 invalid elements: element 1: Email: value must not be the zero value, Age: value is out of range")
+}
+
+func TestErrors_Unwrap(t *testing.T) {
+	_, err := Wrap(signups).Validate(Rules{Checks: []Rule{validate.Range("Age", 13, 130)}}).Release()
+
+	assert.True(t, errors.Is(err, validate.OutOfRangeError))
+	assert.False(t, errors.Is(err, validate.ZeroValueError))
+}
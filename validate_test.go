@@ -0,0 +1,60 @@
+package kundalini_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+	"gitlab.com/jdbellamy/kundalini/validate"
+)
+
+func TestValidate(t *testing.T) {
+	positive := Rule(func(e interface{}) error {
+		if e.(int) <= 0 {
+			return validate.OutOfRangeError
+		}
+		return nil
+	})
+
+	t.Run("should send invalid elements to the side output", func(t *testing.T) {
+		rejected := make([]int, 0)
+		onInvalid := func(inv *Invalid) { rejected = append(rejected, inv.Value.(int)) }
+
+		actual, err := Wrap([]int{3, -1, 0, 7}).
+			Validate(Rules{Checks: []Rule{positive}, OnInvalid: onInvalid}).
+			Map(func(x interface{}) interface{} { return x.(int) * 2 }).
+			Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{6, 14}, actual)
+		assert.Equal(t, []int{-1, 0}, rejected)
+	})
+
+	t.Run("should collect invalid elements into the chain's error", func(t *testing.T) {
+		actual, err := Wrap(&counter{to: 3}).
+			Validate(Rules{Checks: []Rule{positive}}).
+			Release()
+
+		assert.Nil(t, actual)
+		assert.Equal(t, ValidationErrors{{Index: 0, Value: 0, Errs: []error{validate.OutOfRangeError}}}, err)
+	})
+
+	t.Run("should keep valid input unchanged", func(t *testing.T) {
+		actual, err := Wrap([]int{1, 2}).Validate(Rules{Checks: []Rule{positive}}).Release()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, actual)
+	})
+
+	t.Run("should raise error when input type is not supported", func(t *testing.T) {
+		_, err := Wrap(0).Validate(Rules{}).Release()
+
+		assert.Equal(t, UnsupportedWrappedTypeError, err)
+	})
+
+	t.Run("should forward received error", func(t *testing.T) {
+		_, err := Wrap(0).Types().Validate(Rules{Checks: []Rule{positive}}).Release()
+
+		assert.Equal(t, UnsupportedWrappedTypeError, err)
+	})
+}
@@ -0,0 +1,94 @@
+package kundalini
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.com/jdbellamy/kundalini/iterators"
+)
+
+// Timestamp extracts the event time of an element
+type Timestamp func(interface{}) time.Time
+
+// WindowSpec describes how `Window` groups elements by event time
+type WindowSpec = iterators.WindowSpec
+
+// Pane is a window emitted by `Window` and the elements that fell into it
+type Pane = iterators.Pane
+
+// Tumbling windows are back to back and `size` long
+func Tumbling(size time.Duration) WindowSpec { return iterators.Tumbling(size) }
+
+// Sliding windows are `size` long and start every `slide`, which may not
+// exceed `size`
+func Sliding(size, slide time.Duration) WindowSpec { return iterators.Sliding(size, slide) }
+
+// Session windows group elements that are less than `gap` apart
+func Session(gap time.Duration) WindowSpec { return iterators.Session(gap) }
+
+// WithLateness makes `Window` wait for elements up to `d` older than the
+// latest event time before emitting the windows they fall into
+func WithLateness(d time.Duration) StageOption {
+	return func(c *stageConfig) {
+		c.mark.Lateness = d
+	}
+}
+
+// WithIdle makes `Window` advance its watermark with the clock once no
+// element has arrived for `d`, so windows are emitted while a source is
+// quiet, this needs a channel source, optionally behind `Map` and `Filter`
+func WithIdle(d time.Duration) StageOption {
+	return func(c *stageConfig) {
+		c.mark.Idle = d
+	}
+}
+
+// Window groups the elements of `k` into the windows of `spec` by the event
+// time `ts` returns and replaces them with a `Pane` for each window, emitted
+// once the latest event time seen passes its end
+// an element that arrives after its windows were emitted is rejected in
+// dead-letter mode, otherwise `iterators.LateElementError` becomes the
+// chain's error
+// the result is lazy when `k` is, `opts` can allow lateness, emit windows of
+// idle sources and replace the clock
+func (k *K) Window(ts Timestamp, spec WindowSpec, opts ...StageOption) Kundalini {
+	if k.err != nil {
+		return k
+	}
+	c := newStageConfig(opts)
+	c.mark.Clock = c.clock
+	stage := k.stage
+	reject := k.reject()
+	if reject != nil {
+		letters := reject
+		reject = func(v interface{}, err error) {
+			letters(v, &Rejection{Stage: stage, Value: v, Err: err})
+		}
+	}
+
+	it := iterators.Window(k.Iterator(), ts, spec, c.mark, reject)
+	if _, lazy := k.wrapped.(Iterator); lazy {
+		logrus.Debug("window: ", it)
+		return k.next("Window", ts, it)
+	}
+	v, err := iterators.Drain(it)
+	logrus.Debug("window: ", v)
+	if err != nil {
+		return k.fail("Window", err)
+	}
+	panes := make([]Pane, len(v))
+	for i, p := range v {
+		panes[i] = p.(Pane)
+	}
+	return k.next("Window", ts, panes)
+}
+
+// fromChan receives the elements of a channel through an `Iterator`
+func fromChan(e interface{}) (Iterator, bool) {
+	v := reflect.ValueOf(e)
+	if v.Kind() != reflect.Chan || v.Type().ChanDir()&reflect.RecvDir == 0 {
+		return nil, false
+	}
+	return iterators.FromChan(v), true
+}
@@ -0,0 +1,122 @@
+package kundalini_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "gitlab.com/jdbellamy/kundalini"
+	"gitlab.com/jdbellamy/kundalini/iterators"
+)
+
+type Event struct {
+	At    int
+	Value string
+}
+
+func at(s int) time.Time                { return time.Unix(int64(s), 0) }
+func eventTime(x interface{}) time.Time { return at(x.(Event).At) }
+func pane(start, end int, es ...Event) Pane {
+	elements := make([]interface{}, len(es))
+	for i, e := range es {
+		elements[i] = e
+	}
+	return Pane{Start: at(start), End: at(end), Elements: elements}
+}
+
+func TestWindow(t *testing.T) {
+	a, b, c, d, e, f := Event{1, "a"}, Event{3, "b"}, Event{6, "c"}, Event{12, "d"}, Event{21, "e"}, Event{5, "f"}
+
+	t.Run("should group elements into windows", func(t *testing.T) {
+		type Test struct {
+			spec     WindowSpec
+			input    []Event
+			expected []Pane
+		}
+		tests := []Test{
+			{Tumbling(10 * time.Second), []Event{a, b, c, d, e}, []Pane{
+				pane(0, 10, a, b, c), pane(10, 20, d), pane(20, 30, e),
+			}},
+			{Sliding(10*time.Second, 5*time.Second), []Event{a, c, d}, []Pane{
+				pane(-5, 5, a), pane(0, 10, a, c), pane(5, 15, c, d), pane(10, 20, d),
+			}},
+			{Session(5 * time.Second), []Event{a, b, c, d, e}, []Pane{
+				pane(1, 11, a, b, c), pane(12, 17, d), pane(21, 26, e),
+			}},
+			{Session(5 * time.Second), []Event{a, f, b}, []Pane{
+				pane(1, 10, a, f, b),
+			}},
+			{Tumbling(10 * time.Second), []Event{}, []Pane{}},
+		}
+		for _, test := range tests {
+			actual, err := Wrap(test.input).Window(eventTime, test.spec).Release()
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		}
+	})
+
+	t.Run("should wait for late elements within the lateness", func(t *testing.T) {
+		actual, err := Wrap([]Event{a, d, b}).
+			Window(eventTime, Tumbling(10*time.Second), WithLateness(5*time.Second)).
+			Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []Pane{pane(0, 10, a, b), pane(10, 20, d)}, actual)
+	})
+
+	t.Run("should fail on late elements", func(t *testing.T) {
+		actual, err := Wrap([]Event{a, d, b}).Window(eventTime, Tumbling(10*time.Second)).Release()
+		assert.EqualError(t, err, iterators.LateElementError.Error())
+		assert.Nil(t, actual)
+	})
+
+	t.Run("should reject late elements in dead-letter mode", func(t *testing.T) {
+		k := Wrap([]Event{a, d, b}).DeadLetter().Window(eventTime, Tumbling(10*time.Second))
+		actual, err := k.Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []Pane{pane(0, 10, a), pane(10, 20, d)}, actual)
+		assert.Equal(t, []Rejection{{Stage: 0, Value: b, Err: iterators.LateElementError}}, k.Rejected())
+	})
+
+	t.Run("should window a channel lazily", func(t *testing.T) {
+		ch := make(chan Event, 3)
+		ch <- a
+		ch <- d
+		ch <- e
+		close(ch)
+
+		actual, err := Wrap(ch).
+			Window(eventTime, Tumbling(10*time.Second)).
+			Map(func(x interface{}) interface{} { return len(x.(Pane).Elements) }).
+			Release()
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{1, 1, 1}, actual)
+	})
+
+	t.Run("should emit the windows of an idle channel by the clock", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		ch := make(chan Event, 2)
+		ch <- a
+		ch <- b
+
+		it := Wrap(ch).
+			Window(eventTime, Tumbling(10*time.Second), WithIdle(time.Second), WithClock(clock)).
+			Iterator()
+		v, ok := it.Next()
+		assert.True(t, ok)
+		assert.Equal(t, pane(0, 10, a, b), v)
+		assert.Equal(t, time.Unix(7, 0), clock.now)
+
+		close(ch)
+		_, ok = it.Next()
+		assert.False(t, ok)
+	})
+
+	t.Run("should reject invalid windows", func(t *testing.T) {
+		specs := []WindowSpec{Tumbling(0), Sliding(time.Second, -1), Sliding(time.Second, 2*time.Second), Session(-time.Second)}
+		for _, spec := range specs {
+			actual, err := Wrap([]Event{a}).Window(eventTime, spec).Release()
+			assert.EqualError(t, err, iterators.InvalidWindowError.Error())
+			assert.Nil(t, actual)
+		}
+	})
+}